@@ -2,103 +2,344 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"mini-ecommerce/internal/config"
 	"mini-ecommerce/internal/database"
+	"mini-ecommerce/internal/database/migrations"
+	idempotencyDomain "mini-ecommerce/internal/domain/idempotency"
+	userDomain "mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/gateway"
+	"mini-ecommerce/internal/handler/address"
+	"mini-ecommerce/internal/handler/auth"
 	"mini-ecommerce/internal/handler/cart"
 	"mini-ecommerce/internal/handler/category"
+	"mini-ecommerce/internal/handler/health"
 	"mini-ecommerce/internal/handler/order"
+	"mini-ecommerce/internal/handler/payment"
+	"mini-ecommerce/internal/handler/pricing"
 	"mini-ecommerce/internal/handler/product"
+	"mini-ecommerce/internal/handler/promotion"
+	"mini-ecommerce/internal/handler/rma"
+	"mini-ecommerce/internal/handler/shipment"
 	"mini-ecommerce/internal/handler/user"
 	"mini-ecommerce/internal/helper"
 	"mini-ecommerce/internal/middleware"
 	"mini-ecommerce/internal/repository"
 	"mini-ecommerce/internal/service"
+	"mini-ecommerce/internal/storage"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	err := godotenv.Load()
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Error load .env: %v", err)
+		log.Fatal(err)
 	}
 
+	helper.ConfigureTokens(cfg.JWT.Secret, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL)
+
 	ctx := context.Background()
 
-	db, err := database.Connect(ctx)
+	db, err := database.Connect(ctx, cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to connect db m: %v", err)
 	}
-	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Failed to load migrations : %v", err)
+	}
+
+	if cfg.Database.RequireLatestSchema {
+		if err := migrator.EnsureLatest(ctx); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	blob, err := storage.NewLocal(cfg.Storage.Dir, cfg.Storage.PublicURL)
+	if err != nil {
+		log.Fatalf("Failed to open storage : %v", err)
+	}
 
 	tx := helper.NewTransaction(db)
 
-	productRepository := repository.NewProduct(db, tx)
-	productService := service.NewProduct(productRepository)
-	productHandler := product.NewHandler(productService)
+	productRepository := repository.NewProduct(tx)
+	productVariantRepository := repository.NewProductVariant(tx)
+	productImageRepository := repository.NewProductImage(tx)
+	categoryRepository := repository.NewCategory(tx)
+	productService := service.NewProduct(tx, productRepository, productVariantRepository, productImageRepository, categoryRepository, blob, cfg.Store.Currency)
+	productImageService := service.NewProductImage(tx, productRepository, productImageRepository, blob, cfg.Storage.MaxImageBytes)
+	productHandler := product.NewHandler(productService, productImageService, cfg.Storage.MaxImageBytes)
 
-	categoryRepository := repository.NewCategory(db)
-	categoryService := service.NewCategory(categoryRepository)
+	categoryService := service.NewCategory(tx, categoryRepository)
 	categoryHandler := category.NewHandler(categoryService)
 
 	userRepository := repository.NewUser(db)
 	userService := service.NewUser(userRepository)
 	userHandler := user.NewHandler(userService)
 
-	cartRepository := repository.NewCart(tx)
-	cartItemRepository := repository.NewCartItem(tx)
-	cartService := service.NewCart(tx, cartRepository, cartItemRepository)
-	cartHandler := cart.NewHandler(cartService)
+	refreshTokenRepository := repository.NewRefreshToken(tx)
+	authService := service.NewAuth(tx, refreshTokenRepository, userRepository)
+	authHandler := auth.NewHandler(authService)
+
+	couponRepository := repository.NewCoupon(tx)
+	couponRedemptionRepository := repository.NewCouponRedemption(tx)
+	promotionService := service.NewPromotion(tx, couponRepository, couponRedemptionRepository, cfg.Store.Currency)
+	promotionHandler := promotion.NewHandler(promotionService)
+
+	shippingRateRepository := repository.NewShippingRate(tx)
+	taxRateRepository := repository.NewTaxRate(tx)
+	pricingService := service.NewPricing(tx, shippingRateRepository, taxRateRepository, cfg.Store.Currency)
+	pricingHandler := pricing.NewHandler(pricingService)
+
+	addressRepository := repository.NewAddress(tx)
+	addressService := service.NewAddress(tx, addressRepository)
+	addressHandler := address.NewHandler(addressService)
 
 	orderRepository := repository.NewOrder(tx)
 	orderItemRepository := repository.NewOrderItem(tx)
-	orderService := service.NewOrder(tx, orderRepository, orderItemRepository)
+	shipmentRepository := repository.NewShipment(tx)
+	orderService := service.NewOrder(tx, orderRepository, orderItemRepository, productVariantRepository, promotionService, pricingService, addressRepository, shipmentRepository)
 	orderHandler := order.NewHandler(orderService)
 
+	shipmentService := service.NewShipment(tx, shipmentRepository, orderRepository, orderItemRepository, orderService)
+	shipmentHandler := shipment.NewHandler(shipmentService)
+
+	cartRepository := repository.NewCart(tx)
+	cartItemRepository := repository.NewCartItem(tx)
+	cartService := service.NewCart(tx, cartRepository, cartItemRepository, productVariantRepository, orderService)
+	cartHandler := cart.NewHandler(cartService)
+
+	paymentRepository := repository.NewPayment(tx)
+	paymentGateway := gateway.NewFakePayment()
+	paymentService := service.NewPayment(tx, paymentRepository, orderRepository, paymentGateway, cfg.Payment.GatewayTimeout, cfg.Payment.ReconcileAfter)
+	paymentHandler := payment.NewHandler(paymentService)
+
+	returnRepository := repository.NewReturn(tx)
+	refundRepository := repository.NewRefund(tx)
+	returnService := service.NewReturn(tx, returnRepository, refundRepository, orderRepository, orderItemRepository, paymentRepository, productVariantRepository, paymentGateway, orderService, cfg.Payment.GatewayTimeout, cfg.Payment.ReconcileAfter)
+	returnHandler := rma.NewHandler(returnService)
+
+	idempotencyRepository := repository.NewIdempotency(tx)
+	idempotencyService := service.NewIdempotency(idempotencyRepository, cfg.Idempotency.KeyTTL, cfg.Idempotency.Lease)
+	idempotent := middleware.Idempotency(idempotencyService)
+
+	readiness := helper.NewReadiness()
+	healthHandler := health.NewHandler(readiness, db, migrator)
+
 	r := gin.New()
 
+	// Probes are registered before the global middleware so polling them
+	// every second stays cheap and out of the request log.
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
+	r.GET("/version", healthHandler.Version)
+
+	// Uploaded files are public, like the product pages that link to them.
+	if strings.HasPrefix(cfg.Storage.PublicURL, "/") {
+		r.Static(cfg.Storage.PublicURL, cfg.Storage.Dir)
+	}
+
 	r.Use(
 		middleware.Logger(),
+		middleware.CORS(cfg.CORS.AllowedOrigins),
 		middleware.ErrorHandler(),
 		middleware.RequestID(),
 	)
 
 	// ======================== without token ========================
 	r.POST("/users", userHandler.Create)
-	r.GET("/users", userHandler.GetByEmail)
+	r.POST("/auth/login", authHandler.Login)
+	r.POST("/auth/refresh", authHandler.Refresh)
+	r.POST("/auth/logout", authHandler.Logout)
+
+	// Deprecated: login used to be a GET with a JSON body.
+	r.GET("/users", middleware.Deprecated("/auth/login"), authHandler.Login)
 
 	// ======================== with token ========================
 	api := r.Group("/api")
 	api.Use(middleware.JWTAuth())
 
-	api.POST("/products", productHandler.Create)
+	adminOnly := middleware.RequireRole(userDomain.RoleAdmin)
+
+	api.POST("/products", adminOnly, productHandler.Create)
+	api.GET("/products/search", productHandler.Search)
 	api.GET("/products/:id", productHandler.Get)
 	api.GET("/products", productHandler.GetAll)
-	api.PUT("/products", productHandler.Update)
-	api.DELETE("/products/:id", productHandler.Delete)
+	api.PUT("/products", adminOnly, productHandler.Update)
+	api.DELETE("/products/:id", adminOnly, productHandler.Delete)
+	api.POST("/products/:id/variants", adminOnly, productHandler.AddVariant)
+	api.PUT("/products/:id/variants/:variant_id", adminOnly, productHandler.UpdateVariant)
+	api.DELETE("/products/:id/variants/:variant_id", adminOnly, productHandler.DeleteVariant)
+	api.POST("/products/:id/images", adminOnly, productHandler.UploadImage)
+	api.PUT("/products/:id/images/order", adminOnly, productHandler.ReorderImages)
+	api.PUT("/products/:id/images/:image_id/primary", adminOnly, productHandler.SetPrimaryImage)
+	api.DELETE("/products/:id/images/:image_id", adminOnly, productHandler.DeleteImage)
 
-	api.POST("/categories", categoryHandler.Create)
+	api.POST("/categories", adminOnly, categoryHandler.Create)
+	api.GET("/categories/tree", categoryHandler.GetTree)
 	api.GET("/categories/:id", categoryHandler.Get)
 	api.GET("/categories", categoryHandler.GetAll)
-	api.PUT("/categories", categoryHandler.Update)
-	api.DELETE("/categories/:id", categoryHandler.Delete)
+	api.PUT("/categories", adminOnly, categoryHandler.Update)
+	api.DELETE("/categories/:id", adminOnly, categoryHandler.Delete)
+
+	api.POST("/coupons", adminOnly, promotionHandler.Create)
+	api.GET("/coupons/:id", adminOnly, promotionHandler.Get)
+	api.GET("/coupons", adminOnly, promotionHandler.GetAll)
+	api.DELETE("/coupons/:id", adminOnly, promotionHandler.Deactivate)
+
+	api.POST("/shipping-rates", adminOnly, pricingHandler.CreateShippingRate)
+	api.GET("/shipping-rates", adminOnly, pricingHandler.GetShippingRates)
+	api.DELETE("/shipping-rates/:id", adminOnly, pricingHandler.DeleteShippingRate)
+
+	api.POST("/tax-rates", adminOnly, pricingHandler.CreateTaxRate)
+	api.GET("/tax-rates", adminOnly, pricingHandler.GetTaxRates)
+	api.DELETE("/tax-rates/:id", adminOnly, pricingHandler.DeleteTaxRate)
+
+	api.POST("/auth/logout-all", authHandler.LogoutAll)
 
 	api.PUT("/users", userHandler.Update)
 	api.DELETE("/users", userHandler.Delete)
+	api.PUT("/users/:id/role", adminOnly, userHandler.UpdateRole)
+	api.POST("/users/addresses", addressHandler.Create)
+	api.GET("/users/addresses", addressHandler.GetAll)
+	api.GET("/users/addresses/:id", addressHandler.Get)
+	api.PUT("/users/addresses/:id", addressHandler.Update)
+	api.DELETE("/users/addresses/:id", addressHandler.Delete)
 
 	api.POST("/carts", cartHandler.AddItem)
 	api.GET("/carts", cartHandler.GetItems)
 	api.PUT("/carts", cartHandler.UpdateItemQuantity)
 	api.DELETE("/carts/:cart_item_id", cartHandler.DeleteItem)
+	api.POST("/carts/checkout", idempotent, cartHandler.Checkout)
 
-	api.POST("/orders", orderHandler.Create)
+	api.POST("/orders", idempotent, orderHandler.Create)
 	api.GET("/orders/:id", orderHandler.Get)
 	api.GET("/orders", orderHandler.GetAll)
-	api.PUT("/orders/:id/status", orderHandler.Update)
+	api.PUT("/orders/:id/status", adminOnly, orderHandler.Update)
 	api.POST("/orders/:id/cancel", orderHandler.Cancel)
+	api.GET("/orders/:id/payments", paymentHandler.GetByOrder)
+	api.POST("/orders/:id/shipments", adminOnly, shipmentHandler.Create)
+	api.GET("/orders/:id/shipments", shipmentHandler.GetByOrder)
+	api.POST("/orders/:id/returns", returnHandler.Create)
+	api.GET("/orders/:id/returns", returnHandler.GetByOrder)
+
+	api.GET("/shipments/:id", shipmentHandler.Get)
+	api.PUT("/shipments/:id", adminOnly, shipmentHandler.Update)
+	api.POST("/shipments/:id/events", adminOnly, shipmentHandler.AddEvent)
+
+	api.GET("/returns", adminOnly, returnHandler.GetAll)
+	api.GET("/returns/:id", returnHandler.Get)
+	api.POST("/returns/:id/approve", adminOnly, returnHandler.Approve)
+	api.POST("/returns/:id/reject", adminOnly, returnHandler.Reject)
+	api.POST("/returns/:id/receive", adminOnly, returnHandler.Receive)
+	api.POST("/returns/:id/refund", adminOnly, idempotent, returnHandler.Refund)
+
+	api.POST("/payments", idempotent, paymentHandler.Create)
+	api.GET("/payments/:id", paymentHandler.Get)
+
+	srv := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           r,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+		MaxHeaderBytes:    cfg.HTTP.MaxHeaderBytes,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server listening on %s", srv.Addr)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	stop, cancelStop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancelStop()
+
+	go purgeIdempotencyKeys(stop, idempotencyService, cfg.Idempotency.PurgeInterval)
+	go reconcile(stop, "payments", paymentService.Reconcile, cfg.Payment.ReconcileInterval)
+	go reconcile(stop, "refunds", returnService.Reconcile, cfg.Payment.ReconcileInterval)
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Server failed : %v", err)
+			exitCode = 1
+		}
+	case <-stop.Done():
+		cancelStop()
+		log.Print("Shutdown signal received, draining connections")
+
+		readiness.StartDraining()
+		time.Sleep(cfg.HTTP.ShutdownDelay)
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+		defer cancelShutdown()
+
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Server did not drain in time : %v", err)
+			exitCode = 1
+		}
+	}
+
+	// Closing the pool waits for handlers still holding connections, so it
+	// must come after the server has stopped handing out requests.
+	db.Close()
+	log.Print("Server stopped")
+	os.Exit(exitCode)
+}
+
+// purgeIdempotencyKeys deletes expired Idempotency-Key records every interval
+// until ctx is done.
+func purgeIdempotencyKeys(ctx context.Context, idempotencyService idempotencyDomain.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, appErr := idempotencyService.PurgeExpired(ctx)
+			if appErr != nil {
+				log.Printf("Failed to purge idempotency keys : %v", appErr)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Purged %d expired idempotency keys", deleted)
+			}
+		}
+	}
+}
+
+// reconcile runs settle every interval until ctx is done. It settles the
+// payments or refunds, named by what, that the gateway left pending.
+func reconcile(ctx context.Context, what string, settle func(ctx context.Context) (int, *helper.AppError), interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	if err := r.Run(":8080"); err != nil {
-		log.Fatalf("Server failed : %v", err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			settled, appErr := settle(ctx)
+			if appErr != nil {
+				log.Printf("Failed to reconcile %s : %v", what, appErr)
+			}
+			if settled > 0 {
+				log.Printf("Reconciled %d pending %s", settled, what)
+			}
+		}
 	}
 }
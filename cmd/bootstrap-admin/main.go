@@ -0,0 +1,65 @@
+// Command bootstrap-admin grants the admin role to the first administrator.
+//
+// Promote an existing account:
+//
+//	go run ./cmd/bootstrap-admin -email admin@example.com
+//
+// Or create the account as admin:
+//
+//	go run ./cmd/bootstrap-admin -email admin@example.com -name Admin -password secret
+//
+// It refuses to run once an admin exists.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"mini-ecommerce/internal/config"
+	"mini-ecommerce/internal/database"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/repository"
+	"mini-ecommerce/internal/service"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the account to make admin (required)")
+	name := flag.String("name", "", "name for a new account")
+	password := flag.String("password", "", "password for a new account; leave empty to promote an existing one")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		log.Fatal("-email is required")
+	}
+
+	if *password != "" && *name == "" {
+		log.Fatal("-name is required when creating a new account")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect db : %v", err)
+	}
+	defer db.Close()
+
+	userService := service.NewUser(repository.NewUser(db))
+
+	userData := user.Data{
+		Name:     *name,
+		Email:    *email,
+		Password: *password,
+	}
+	if appErr := userService.BootstrapAdmin(ctx, &userData); appErr != nil {
+		log.Fatalf("%s : %v", appErr.Message, appErr)
+	}
+
+	log.Printf("%s is now an admin", userData.Email)
+}
@@ -0,0 +1,144 @@
+// Command migrate manages the database schema.
+//
+//	go run ./cmd/migrate up [N]       apply all pending migrations, or the next N
+//	go run ./cmd/migrate down [N]     roll back the newest migration, or the newest N
+//	go run ./cmd/migrate status       list migrations and when they were applied
+//	go run ./cmd/migrate create NAME  add an empty up/down pair to -dir
+//
+// Migrations are embedded in the binary; -dir is only used by create.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"mini-ecommerce/internal/config"
+	"mini-ecommerce/internal/database"
+	"mini-ecommerce/internal/database/migrations"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func main() {
+	dir := flag.String("dir", "internal/database/migrations", "migrations source directory used by create")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: migrate [-dir path] up [N] | down [N] | status | create NAME")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal("create needs a NAME")
+		}
+		if err := create(*dir, args[1]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect db : %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		log.Fatalf("Load migrations : %v", err)
+	}
+
+	steps := 0
+	if len(args) > 1 {
+		steps, err = strconv.Atoi(args[1])
+		if err != nil || steps < 1 {
+			log.Fatalf("N must be a positive number, got %q", args[1])
+		}
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx, steps)
+		for _, migration := range done {
+			log.Printf("Applied %06d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			log.Print("Schema is up to date")
+		}
+	case "down":
+		done, err := migrator.Down(ctx, steps)
+		for _, migration := range done {
+			log.Printf("Rolled back %06d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			log.Print("Nothing to roll back")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%06d  %-40s  %s\n", status.Migration.Version, status.Migration.Name, appliedAt)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// create writes an empty up/down pair numbered after the newest migration in
+// dir.
+func create(dir string, name string) error {
+	if !migrationNamePattern.MatchString(name) {
+		return fmt.Errorf("Migration name must be lower snake case, got %q", name)
+	}
+
+	existing, err := database.LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+
+	var version int64 = 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", version, name, direction))
+		if err := os.WriteFile(path, []byte("-- "+direction+"\n"), 0o644); err != nil {
+			return err
+		}
+		log.Printf("Created %s", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"mini-ecommerce/internal/money"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/joho/godotenv"
+)
+
+type Config struct {
+	Database    Database
+	HTTP        HTTP
+	JWT         JWT
+	CORS        CORS
+	Store       Store
+	Idempotency Idempotency
+	Payment     Payment
+	Storage     Storage
+}
+
+type Database struct {
+	URL             string
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	// RequireLatestSchema makes the API refuse to start while migrations
+	// bundled with the binary are still pending. /readyz reports pending
+	// migrations either way.
+	RequireLatestSchema bool
+}
+
+type HTTP struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	// ShutdownDelay is how long the server keeps serving after it reports not
+	// ready, giving load balancers time to stop routing to it.
+	ShutdownDelay time.Duration
+	// ShutdownTimeout bounds how long in-flight requests may take to finish.
+	ShutdownTimeout time.Duration
+}
+
+type JWT struct {
+	Secret          string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+type CORS struct {
+	AllowedOrigins []string
+}
+
+type Store struct {
+	// Currency is the ISO 4217 code products are priced in when a request
+	// does not name one.
+	Currency string
+}
+
+type Idempotency struct {
+	// KeyTTL is how long a stored response can be replayed for the same
+	// Idempotency-Key.
+	KeyTTL time.Duration
+	// Lease is how long a request still being processed holds its key. A
+	// retry after that runs again, so a crashed request does not block the
+	// key until KeyTTL.
+	Lease time.Duration
+	// PurgeInterval is how often expired keys are deleted.
+	PurgeInterval time.Duration
+}
+
+type Payment struct {
+	// GatewayTimeout bounds a single call to the payment provider.
+	GatewayTimeout time.Duration
+	// ReconcileAfter is how long a payment or refund may stay pending before
+	// it is sent to the provider again to learn its outcome.
+	ReconcileAfter time.Duration
+	// ReconcileInterval is how often pending payments and refunds are looked
+	// for.
+	ReconcileInterval time.Duration
+}
+
+type Storage struct {
+	// Dir is where uploaded files are kept on the local filesystem.
+	Dir string
+	// PublicURL is the base URL files are served from. A path such as
+	// "/media" makes the API serve Dir itself.
+	PublicURL string
+	// MaxImageBytes bounds the size of a single uploaded image.
+	MaxImageBytes int64
+}
+
+const minJWTSecretLength = 32
+
+// Load builds the configuration from, in increasing order of precedence,
+// built-in defaults, the YAML file named by CONFIG_FILE, a .env file in the
+// working directory and the process environment. Every key is named after its
+// environment variable; in YAML the same key is nested by its underscore
+// separated prefix, e.g. DATABASE_MAX_CONNS is database.max_conns.
+//
+// All missing or invalid settings are reported together in the returned error.
+func Load() (Config, error) {
+	values := map[string]string{}
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := readYAML(path, values); err != nil {
+			return Config{}, fmt.Errorf("Read config file %s : %w", path, err)
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("Read .env : %w", err)
+	}
+
+	l := &loader{values: values}
+
+	cfg := Config{
+		Database: Database{
+			URL:                 l.required("DATABASE_URL"),
+			MaxConns:            int32(l.int("DATABASE_MAX_CONNS", 10, 1)),
+			MinConns:            int32(l.int("DATABASE_MIN_CONNS", 0, 0)),
+			MaxConnLifetime:     l.duration("DATABASE_MAX_CONN_LIFETIME", time.Hour),
+			MaxConnIdleTime:     l.duration("DATABASE_MAX_CONN_IDLE_TIME", 30*time.Minute),
+			RequireLatestSchema: l.bool("DATABASE_REQUIRE_LATEST_SCHEMA", true),
+		},
+		HTTP: HTTP{
+			Addr:              l.string("HTTP_ADDR", ":8080"),
+			ReadTimeout:       l.duration("HTTP_READ_TIMEOUT", 15*time.Second),
+			ReadHeaderTimeout: l.duration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+			WriteTimeout:      l.duration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+			IdleTimeout:       l.duration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+			MaxHeaderBytes:    l.int("HTTP_MAX_HEADER_BYTES", 1<<20, 4096),
+			ShutdownDelay:     l.optionalDuration("HTTP_SHUTDOWN_DELAY", 0),
+			ShutdownTimeout:   l.duration("HTTP_SHUTDOWN_TIMEOUT", 30*time.Second),
+		},
+		JWT: JWT{
+			Secret:          l.required("JWT_SECRET"),
+			AccessTokenTTL:  l.duration("JWT_ACCESS_TOKEN_TTL", 10*time.Minute),
+			RefreshTokenTTL: l.duration("JWT_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		},
+		CORS: CORS{
+			AllowedOrigins: l.list("CORS_ALLOWED_ORIGINS"),
+		},
+		Store: Store{
+			Currency: strings.ToUpper(l.string("STORE_CURRENCY", "IDR")),
+		},
+		Idempotency: Idempotency{
+			KeyTTL:        l.duration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+			Lease:         l.duration("IDEMPOTENCY_LEASE", 2*time.Minute),
+			PurgeInterval: l.duration("IDEMPOTENCY_PURGE_INTERVAL", time.Hour),
+		},
+		Payment: Payment{
+			GatewayTimeout:    l.duration("PAYMENT_GATEWAY_TIMEOUT", 20*time.Second),
+			ReconcileAfter:    l.duration("PAYMENT_RECONCILE_AFTER", 5*time.Minute),
+			ReconcileInterval: l.duration("PAYMENT_RECONCILE_INTERVAL", time.Minute),
+		},
+		Storage: Storage{
+			Dir:           l.string("STORAGE_DIR", "uploads"),
+			PublicURL:     l.string("STORAGE_PUBLIC_URL", "/media"),
+			MaxImageBytes: int64(l.int("STORAGE_MAX_IMAGE_BYTES", 5<<20, 1024)),
+		},
+	}
+
+	if cfg.Database.MinConns > cfg.Database.MaxConns {
+		l.invalid("DATABASE_MIN_CONNS", "must not be greater than DATABASE_MAX_CONNS")
+	}
+
+	if cfg.JWT.Secret != "" && len(cfg.JWT.Secret) < minJWTSecretLength {
+		l.invalid("JWT_SECRET", fmt.Sprintf("must be at least %d characters", minJWTSecretLength))
+	}
+
+	if cfg.JWT.RefreshTokenTTL <= cfg.JWT.AccessTokenTTL {
+		l.invalid("JWT_REFRESH_TOKEN_TTL", "must be longer than JWT_ACCESS_TOKEN_TTL")
+	}
+
+	if cfg.Idempotency.Lease >= cfg.Idempotency.KeyTTL {
+		l.invalid("IDEMPOTENCY_LEASE", "must be shorter than IDEMPOTENCY_KEY_TTL")
+	}
+
+	if cfg.Payment.ReconcileAfter <= cfg.Payment.GatewayTimeout {
+		l.invalid("PAYMENT_RECONCILE_AFTER", "must be longer than PAYMENT_GATEWAY_TIMEOUT")
+	}
+
+	if !money.IsSupportedCurrency(cfg.Store.Currency) {
+		l.invalid("STORE_CURRENCY", "must be a supported ISO 4217 currency code")
+	}
+
+	if len(l.problems) > 0 {
+		return Config{}, fmt.Errorf("Invalid configuration:\n  - %s", strings.Join(l.problems, "\n  - "))
+	}
+
+	return cfg, nil
+}
+
+// readYAML flattens the nested YAML document into values keyed by the
+// matching environment variable name.
+func readYAML(path string, values map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var document map[string]any
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return err
+	}
+
+	flatten("", document, values)
+	return nil
+}
+
+func flatten(prefix string, node map[string]any, values map[string]string) {
+	for key, value := range node {
+		name := strings.ToUpper(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(name, v, values)
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[name] = strings.Join(items, ",")
+		case nil:
+		default:
+			values[name] = fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// isolate clears the settings the tests rely on so the environment of the
+// machine running them does not leak in.
+func isolate(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	for _, key := range []string{
+		"CONFIG_FILE",
+		"DATABASE_URL",
+		"DATABASE_MAX_CONNS",
+		"DATABASE_MIN_CONNS",
+		"JWT_SECRET",
+		"JWT_ACCESS_TOKEN_TTL",
+		"JWT_REFRESH_TOKEN_TTL",
+		"HTTP_ADDR",
+		"CORS_ALLOWED_ORIGINS",
+		"STORE_CURRENCY",
+	} {
+		// Setenv restores the variable after the test; it is unset here
+		// because a set but empty variable still overrides the file.
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	isolate(t)
+	t.Setenv("DATABASE_URL", "postgres://localhost/shop")
+	t.Setenv("JWT_SECRET", testSecret)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.HTTP.Addr != ":8080" {
+		t.Errorf("HTTP.Addr = %q, want :8080", cfg.HTTP.Addr)
+	}
+	if cfg.Database.MaxConns != 10 {
+		t.Errorf("Database.MaxConns = %d, want 10", cfg.Database.MaxConns)
+	}
+	if cfg.JWT.AccessTokenTTL != 10*time.Minute {
+		t.Errorf("JWT.AccessTokenTTL = %s, want 10m", cfg.JWT.AccessTokenTTL)
+	}
+	if cfg.Store.Currency != "IDR" {
+		t.Errorf("Store.Currency = %q, want IDR", cfg.Store.Currency)
+	}
+	if cfg.CORS.AllowedOrigins != nil {
+		t.Errorf("CORS.AllowedOrigins = %v, want none", cfg.CORS.AllowedOrigins)
+	}
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	isolate(t)
+	t.Setenv("JWT_SECRET", "short")
+	t.Setenv("DATABASE_MAX_CONNS", "many")
+	t.Setenv("STORE_CURRENCY", "xxx")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("Load succeeded with invalid settings")
+	}
+
+	for _, key := range []string{"DATABASE_URL", "JWT_SECRET", "DATABASE_MAX_CONNS", "STORE_CURRENCY"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error does not mention %s: %v", key, err)
+		}
+	}
+}
+
+func TestLoadEnvironmentOverridesFile(t *testing.T) {
+	isolate(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := "database:\n  url: postgres://file/shop\n  max_conns: 5\njwt:\n  secret: " + testSecret + "\ncors:\n  allowed_origins:\n    - https://a.example\n    - https://b.example\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DATABASE_MAX_CONNS", "7")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Database.URL != "postgres://file/shop" {
+		t.Errorf("Database.URL = %q, want the file's", cfg.Database.URL)
+	}
+	if cfg.Database.MaxConns != 7 {
+		t.Errorf("Database.MaxConns = %d, want the environment's 7", cfg.Database.MaxConns)
+	}
+	if got := strings.Join(cfg.CORS.AllowedOrigins, ","); got != "https://a.example,https://b.example" {
+		t.Errorf("CORS.AllowedOrigins = %q", got)
+	}
+}
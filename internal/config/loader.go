@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loader reads typed settings and collects every problem instead of stopping
+// at the first one.
+type loader struct {
+	values   map[string]string
+	problems []string
+}
+
+func (l *loader) lookup(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return strings.TrimSpace(value), true
+	}
+
+	value, ok := l.values[key]
+	return strings.TrimSpace(value), ok
+}
+
+func (l *loader) invalid(key string, reason string) {
+	l.problems = append(l.problems, fmt.Sprintf("%s %s", key, reason))
+}
+
+func (l *loader) string(key string, fallback string) string {
+	if value, ok := l.lookup(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (l *loader) required(key string) string {
+	value, _ := l.lookup(key)
+	if value == "" {
+		l.invalid(key, "is required")
+	}
+	return value
+}
+
+func (l *loader) int(key string, fallback int, min int) int {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("must be a whole number, got %q", value))
+		return fallback
+	}
+
+	if n < min {
+		l.invalid(key, fmt.Sprintf("must be at least %d, got %d", min, n))
+		return fallback
+	}
+
+	return n
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("must be true or false, got %q", value))
+		return fallback
+	}
+
+	return b
+}
+
+func (l *loader) duration(key string, fallback time.Duration) time.Duration {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("must be a duration such as 30s or 10m, got %q", value))
+		return fallback
+	}
+
+	if d <= 0 {
+		l.invalid(key, fmt.Sprintf("must be positive, got %s", d))
+		return fallback
+	}
+
+	return d
+}
+
+func (l *loader) list(key string) []string {
+	value, _ := l.lookup(key)
+	if value == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// optionalDuration is like duration but accepts zero to turn a delay off.
+func (l *loader) optionalDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := l.lookup(key)
+	if !ok || value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		l.invalid(key, fmt.Sprintf("must be a duration such as 0s or 5s, got %q", value))
+		return fallback
+	}
+
+	if d < 0 {
+		l.invalid(key, fmt.Sprintf("must not be negative, got %s", d))
+		return fallback
+	}
+
+	return d
+}
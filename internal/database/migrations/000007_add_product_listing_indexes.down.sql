@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS categories_name_id_idx;
+DROP INDEX IF EXISTS products_created_at_idx;
+DROP INDEX IF EXISTS products_price_idx;
@@ -0,0 +1,3 @@
+CREATE INDEX products_price_idx ON products (price, id);
+CREATE INDEX products_created_at_idx ON products (created_at, id);
+CREATE INDEX categories_name_id_idx ON categories (name, id);
@@ -0,0 +1,15 @@
+ALTER TABLE payments
+    DROP COLUMN currency,
+    ALTER COLUMN amount TYPE NUMERIC(12, 2) USING amount / 100.0;
+
+ALTER TABLE order_items
+    DROP COLUMN currency,
+    ALTER COLUMN price TYPE NUMERIC(12, 2) USING price / 100.0;
+
+ALTER TABLE orders
+    DROP COLUMN currency,
+    ALTER COLUMN total_price TYPE NUMERIC(12, 2) USING total_price / 100.0;
+
+ALTER TABLE products
+    DROP COLUMN currency,
+    ALTER COLUMN price TYPE NUMERIC(12, 2) USING price / 100.0;
@@ -0,0 +1,17 @@
+-- Amounts move from NUMERIC major units to BIGINT minor units with an explicit
+-- ISO 4217 currency. Existing rows are in the store currency (IDR, 2 digits).
+ALTER TABLE products
+    ALTER COLUMN price TYPE BIGINT USING round(price * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE orders
+    ALTER COLUMN total_price TYPE BIGINT USING round(total_price * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE order_items
+    ALTER COLUMN price TYPE BIGINT USING round(price * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE payments
+    ALTER COLUMN amount TYPE BIGINT USING round(amount * 100)::BIGINT,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
@@ -0,0 +1,18 @@
+ALTER TABLE products ADD COLUMN stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0);
+UPDATE products p SET stock = (SELECT COALESCE(SUM(v.stock), 0) FROM product_variants v WHERE v.product_id = p.id);
+
+ALTER TABLE order_items DROP COLUMN variant_id;
+
+-- Lines for different variants of one product collapse into one.
+UPDATE cart_items ci SET quantity = totals.quantity
+FROM (SELECT MIN(id) AS id, SUM(quantity) AS quantity FROM cart_items GROUP BY cart_id, product_id) totals
+WHERE ci.id = totals.id;
+DELETE FROM cart_items a USING cart_items b
+WHERE a.cart_id = b.cart_id AND a.product_id = b.product_id AND a.id > b.id;
+
+ALTER TABLE cart_items
+    DROP CONSTRAINT cart_items_cart_id_variant_id_key,
+    DROP COLUMN variant_id,
+    ADD CONSTRAINT cart_items_cart_id_product_id_key UNIQUE (cart_id, product_id);
+
+DROP TABLE IF EXISTS product_variants;
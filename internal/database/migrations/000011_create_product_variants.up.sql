@@ -0,0 +1,31 @@
+-- Stock moves from products to product_variants. Every product keeps at least
+-- one variant; existing products get a single variant with no options that
+-- carries their stock, and existing cart and order lines point at it.
+CREATE TABLE product_variants (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    options JSONB NOT NULL DEFAULT '{}',
+    -- price overrides products.price when set, in the product currency.
+    price BIGINT CHECK (price > 0),
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (product_id, options)
+);
+
+INSERT INTO product_variants (product_id, sku, stock)
+SELECT id, 'P' || id || '-1', stock FROM products;
+
+ALTER TABLE cart_items ADD COLUMN variant_id BIGINT REFERENCES product_variants (id) ON DELETE CASCADE;
+UPDATE cart_items ci SET variant_id = v.id FROM product_variants v WHERE v.product_id = ci.product_id;
+ALTER TABLE cart_items
+    ALTER COLUMN variant_id SET NOT NULL,
+    DROP CONSTRAINT cart_items_cart_id_product_id_key,
+    ADD CONSTRAINT cart_items_cart_id_variant_id_key UNIQUE (cart_id, variant_id);
+
+ALTER TABLE order_items ADD COLUMN variant_id BIGINT REFERENCES product_variants (id);
+UPDATE order_items oi SET variant_id = v.id FROM product_variants v WHERE v.product_id = oi.product_id;
+ALTER TABLE order_items ALTER COLUMN variant_id SET NOT NULL;
+
+ALTER TABLE products DROP COLUMN stock;
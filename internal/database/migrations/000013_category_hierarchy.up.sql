@@ -0,0 +1,8 @@
+-- Categories form a forest: parent_id is NULL for top-level categories. The
+-- application rejects moves that would create a cycle; a category cannot be
+-- its own parent even without it.
+ALTER TABLE categories
+    ADD COLUMN parent_id BIGINT REFERENCES categories (id),
+    ADD CONSTRAINT categories_parent_id_check CHECK (parent_id <> id);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
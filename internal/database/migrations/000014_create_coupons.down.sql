@@ -0,0 +1,10 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS free_shipping,
+    DROP COLUMN IF EXISTS coupon_code,
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS subtotal;
+
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupon_categories;
+DROP TABLE IF EXISTS coupon_products;
+DROP TABLE IF EXISTS coupons;
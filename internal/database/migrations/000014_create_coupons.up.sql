@@ -0,0 +1,53 @@
+-- Codes are stored upper case. percent_off is in basis points (1500 is 15%);
+-- amounts are in minor units of currency. A NULL limit is unlimited.
+CREATE TABLE coupons (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('percentage', 'fixed_amount', 'free_shipping')),
+    percent_off INTEGER NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 10000),
+    amount_off BIGINT NOT NULL DEFAULT 0 CHECK (amount_off >= 0),
+    min_order_amount BIGINT NOT NULL DEFAULT 0 CHECK (min_order_amount >= 0),
+    currency CHAR(3) NOT NULL,
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    usage_limit INTEGER CHECK (usage_limit > 0),
+    per_user_limit INTEGER CHECK (per_user_limit > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (starts_at IS NULL OR ends_at IS NULL OR starts_at < ends_at)
+);
+
+-- A coupon without rows in either scope table applies to every product.
+CREATE TABLE coupon_products (
+    coupon_id BIGINT NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, product_id)
+);
+
+CREATE TABLE coupon_categories (
+    coupon_id BIGINT NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+-- One row per order that used a coupon; cancelling the order deletes it.
+CREATE TABLE coupon_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    coupon_id BIGINT NOT NULL REFERENCES coupons (id),
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    order_id BIGINT NOT NULL UNIQUE REFERENCES orders (id) ON DELETE CASCADE,
+    discount BIGINT NOT NULL CHECK (discount >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX coupon_redemptions_coupon_id_user_id_idx ON coupon_redemptions (coupon_id, user_id);
+
+-- total_price stays what the customer pays: subtotal minus discount.
+ALTER TABLE orders
+    ADD COLUMN subtotal BIGINT,
+    ADD COLUMN discount BIGINT NOT NULL DEFAULT 0 CHECK (discount >= 0),
+    ADD COLUMN coupon_code VARCHAR(50),
+    ADD COLUMN free_shipping BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE orders SET subtotal = total_price;
+ALTER TABLE orders ALTER COLUMN subtotal SET NOT NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS tax,
+    DROP COLUMN IF EXISTS shipping,
+    DROP COLUMN IF EXISTS region;
+
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS shipping_rates;
+
+ALTER TABLE products DROP COLUMN IF EXISTS weight_grams;
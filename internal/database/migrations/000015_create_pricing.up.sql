@@ -0,0 +1,35 @@
+-- Shipping weight of one unit of the product, shared by its variants.
+ALTER TABLE products ADD COLUMN weight_grams INTEGER NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+
+-- An order ships at the fee of the rate with the highest min_weight_grams not
+-- above its weight, preferring rates for its region over those with a NULL
+-- region, which apply everywhere. A single NULL region rate at weight 0 is a
+-- flat fee.
+CREATE TABLE shipping_rates (
+    id BIGSERIAL PRIMARY KEY,
+    region VARCHAR(20),
+    min_weight_grams INTEGER NOT NULL DEFAULT 0 CHECK (min_weight_grams >= 0),
+    fee BIGINT NOT NULL CHECK (fee >= 0),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX shipping_rates_region_currency_weight_idx ON shipping_rates (COALESCE(region, ''), currency, min_weight_grams);
+
+-- basis_points is the tax rate, so 1100 is 11%. A NULL region or category
+-- matches any; a category also covers its subcategories.
+CREATE TABLE tax_rates (
+    id BIGSERIAL PRIMARY KEY,
+    region VARCHAR(20),
+    category_id BIGINT REFERENCES categories (id) ON DELETE CASCADE,
+    basis_points INTEGER NOT NULL CHECK (basis_points BETWEEN 0 AND 10000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX tax_rates_region_category_idx ON tax_rates (COALESCE(region, ''), COALESCE(category_id, 0));
+
+-- total_price is now subtotal - discount + shipping + tax.
+ALTER TABLE orders
+    ADD COLUMN region VARCHAR(20),
+    ADD COLUMN shipping BIGINT NOT NULL DEFAULT 0 CHECK (shipping >= 0),
+    ADD COLUMN tax BIGINT NOT NULL DEFAULT 0 CHECK (tax >= 0);
@@ -0,0 +1,24 @@
+-- region is the code shipping and tax rates are matched on; empty matches
+-- only the rates for every region.
+CREATE TABLE addresses (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    recipient VARCHAR(100) NOT NULL,
+    phone VARCHAR(30) NOT NULL,
+    line1 VARCHAR(200) NOT NULL,
+    line2 VARCHAR(200) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    postal_code VARCHAR(20) NOT NULL,
+    region VARCHAR(20) NOT NULL DEFAULT '',
+    country CHAR(2) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses (user_id);
+CREATE UNIQUE INDEX addresses_default_idx ON addresses (user_id) WHERE is_default;
+
+-- A copy of the address the order ships to, so later changes to the address
+-- book leave the order as it was placed.
+ALTER TABLE orders ADD COLUMN shipping_address JSONB;
@@ -0,0 +1,35 @@
+-- A shipment carries some or all of the items of an order. status only moves
+-- forward: shipped, in_transit, delivered.
+CREATE TABLE shipments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    carrier VARCHAR(50) NOT NULL,
+    tracking_number VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'shipped' CHECK (status IN ('shipped', 'in_transit', 'delivered')),
+    shipped_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX shipments_order_id_idx ON shipments (order_id);
+
+CREATE TABLE shipment_items (
+    shipment_id BIGINT NOT NULL REFERENCES shipments (id) ON DELETE CASCADE,
+    order_item_id BIGINT NOT NULL REFERENCES order_items (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (shipment_id, order_item_id)
+);
+
+-- The tracking timeline of a shipment.
+CREATE TABLE shipment_events (
+    id BIGSERIAL PRIMARY KEY,
+    shipment_id BIGINT NOT NULL REFERENCES shipments (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('shipped', 'in_transit', 'delivered')),
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    location VARCHAR(100) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX shipment_events_shipment_id_idx ON shipment_events (shipment_id, occurred_at);
@@ -0,0 +1,5 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS refunded;
+
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS returns;
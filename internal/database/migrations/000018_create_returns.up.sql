@@ -0,0 +1,42 @@
+-- A customer's request to send back items of an order. status moves from
+-- requested to approved or rejected, then received and refunded.
+CREATE TABLE returns (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'approved', 'rejected', 'received', 'refunded')),
+    reason VARCHAR(500) NOT NULL,
+    resolution_note VARCHAR(500) NOT NULL DEFAULT '',
+    restocked BOOLEAN NOT NULL DEFAULT FALSE,
+    refunded BIGINT NOT NULL DEFAULT 0 CHECK (refunded >= 0),
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX returns_order_id_idx ON returns (order_id);
+CREATE INDEX returns_status_idx ON returns (status, created_at);
+
+CREATE TABLE return_items (
+    return_id BIGINT NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    order_item_id BIGINT NOT NULL REFERENCES order_items (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (return_id, order_item_id)
+);
+
+-- Every refund attempt sent to the payment gateway, including failed ones.
+CREATE TABLE refunds (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    payment_id BIGINT NOT NULL REFERENCES payments (id),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'success', 'failed')),
+    reference VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX refunds_return_id_idx ON refunds (return_id);
+
+-- refunded is the total paid back on the order so far.
+ALTER TABLE orders ADD COLUMN refunded BIGINT NOT NULL DEFAULT 0 CHECK (refunded >= 0);
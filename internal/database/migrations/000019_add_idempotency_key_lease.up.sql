@@ -0,0 +1,5 @@
+-- locked_until bounds how long an unfinished request holds its key, so a
+-- retry can take over the key of a request whose process died.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMPTZ;
+
+UPDATE idempotency_keys SET locked_until = NOW() WHERE status_code IS NULL;
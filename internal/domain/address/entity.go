@@ -0,0 +1,39 @@
+package address
+
+// Address is a postal address. Region is the code shipping and tax rates are
+// matched on and Country an ISO 3166-1 alpha-2 code.
+type Address struct {
+	Recipient  string
+	Phone      string
+	Line1      string
+	Line2      string
+	City       string
+	PostalCode string
+	Region     string
+	Country    string
+}
+
+// Data is an entry in a user's address book. At most one entry per user is
+// the Default, which orders ship to when no address is chosen.
+type Data struct {
+	ID      string
+	UserID  int
+	Address Address
+	Default bool
+}
+
+// Update changes the fields that are not nil. Setting Default moves the
+// default away from the user's other addresses.
+type Update struct {
+	ID         string
+	UserID     int
+	Recipient  *string
+	Phone      *string
+	Line1      *string
+	Line2      *string
+	City       *string
+	PostalCode *string
+	Region     *string
+	Country    *string
+	Default    *bool
+}
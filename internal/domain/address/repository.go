@@ -0,0 +1,23 @@
+package address
+
+import "context"
+
+type Repository interface {
+	// LockByUserId serialises changes to the address book of userId until
+	// the surrounding transaction ends, so it keeps a single default.
+	LockByUserId(ctx context.Context, userId int) error
+	Create(ctx context.Context, data *Data) error
+	// FindById only finds addresses of userId.
+	FindById(ctx context.Context, userId int, id string) (Data, error)
+	FindByUserId(ctx context.Context, userId int) ([]Data, error)
+	// FindDefault returns helper.ErrAddressNotFound when userId has no
+	// default address.
+	FindDefault(ctx context.Context, userId int) (Data, error)
+	// Update fills update with the stored values of the fields left nil.
+	Update(ctx context.Context, update *Update) error
+	// ClearDefault unsets the default address of userId, if any.
+	ClearDefault(ctx context.Context, userId int) error
+	// PromoteDefault makes the newest address of userId the default.
+	PromoteDefault(ctx context.Context, userId int) error
+	Delete(ctx context.Context, userId int, id string) error
+}
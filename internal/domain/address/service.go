@@ -0,0 +1,18 @@
+package address
+
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	// Create adds data to the address book. The first address becomes the
+	// default.
+	Create(ctx context.Context, data *Data) *helper.AppError
+	Get(ctx context.Context, userId int, id string) (Data, *helper.AppError)
+	GetAll(ctx context.Context, userId int) ([]Data, *helper.AppError)
+	Update(ctx context.Context, update *Update) *helper.AppError
+	// Delete removes the address. Deleting the default makes the newest
+	// remaining address the default.
+	Delete(ctx context.Context, userId int, id string) *helper.AppError
+}
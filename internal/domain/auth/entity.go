@@ -0,0 +1,27 @@
+package auth
+
+import "time"
+
+type RefreshToken struct {
+	ID          int
+	UserID      int
+	FamilyID    string
+	TokenHash   string
+	DeviceLabel string
+	ExpiresAt   time.Time
+	RevokedAt   *time.Time
+}
+
+func (r RefreshToken) IsRevoked() bool {
+	return r.RevokedAt != nil
+}
+
+func (r RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+type TokenPair struct {
+	AccessToken           string
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
@@ -0,0 +1,11 @@
+package auth
+
+import "context"
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	FindByHashForUpdate(ctx context.Context, tokenHash string) (RefreshToken, error)
+	Revoke(ctx context.Context, id int) error
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeAllByUserId(ctx context.Context, userId int) error
+}
@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"context"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	Login(ctx context.Context, login user.Login, deviceLabel string) (user.Data, TokenPair, *helper.AppError)
+	Refresh(ctx context.Context, refreshToken string) (TokenPair, *helper.AppError)
+	Logout(ctx context.Context, refreshToken string) *helper.AppError
+	LogoutAll(ctx context.Context, userId int) *helper.AppError
+}
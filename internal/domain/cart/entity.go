@@ -1,5 +1,7 @@
 package cart
 
+import "fmt"
+
 type Data struct {
 	ID     int
 	UserID int
@@ -9,6 +11,7 @@ type Item struct {
 	ID        int
 	CartID    int
 	ProductID string
+	VariantID string
 	Quantity  int
 }
 
@@ -16,3 +19,22 @@ type UpdateItem struct {
 	ID       int
 	Quantity int
 }
+
+// CheckoutIssue describes why a single cart line could not be turned into an
+// order line. Reason is one of the helper product errors.
+type CheckoutIssue struct {
+	CartItemID int
+	ProductID  string
+	VariantID  string
+	Quantity   int
+	Available  int
+	Reason     error
+}
+
+type CheckoutError struct {
+	Issues []CheckoutIssue
+}
+
+func (e *CheckoutError) Error() string {
+	return fmt.Sprintf("%d cart item(s) cannot be checked out", len(e.Issues))
+}
@@ -11,7 +11,8 @@ type ItemRepository interface {
 	Create(ctx context.Context, item *Item) error
 	FindAllByCartId(ctx context.Context, cartId int) ([]Item, error)
 	FindById(ctx context.Context, itemId int) (Item, error)
-	FindByCartAndProductId(ctx context.Context, cartId int, productId string) (*Item, error)
+	FindByCartAndVariantId(ctx context.Context, cartId int, variantId string) (*Item, error)
 	Update(ctx context.Context, updateItem UpdateItem) error
 	Delete(ctx context.Context, itemId int) error
+	DeleteAllByCartId(ctx context.Context, cartId int) error
 }
@@ -2,12 +2,18 @@ package cart
 
 import (
 	"context"
+	"mini-ecommerce/internal/domain/order"
 	"mini-ecommerce/internal/helper"
 )
 
 type Service interface {
 	GetItems(ctx context.Context, userId int) ([]Item, *helper.AppError)
-	AddItem(ctx context.Context, userId int, productId string, quantity int) (Item, *helper.AppError)
+	// AddItem adds quantity of a variant to the cart. variantId may be left
+	// empty when the product has a single variant.
+	AddItem(ctx context.Context, userId int, productId string, variantId string, quantity int) (Item, *helper.AppError)
 	UpdateItemQuantity(ctx context.Context, userId int, updateItem UpdateItem) *helper.AppError
 	DeleteItem(ctx context.Context, userId int, itemId int) *helper.AppError
+	// Checkout places an order for the cart, priced for the coupon and address
+	// of checkout.
+	Checkout(ctx context.Context, userId int, checkout order.Checkout) (order.Detail, *helper.AppError)
 }
@@ -3,9 +3,27 @@ package category
 type Data struct {
 	ID   string
 	Name string
+	// ParentID is empty for a top-level category.
+	ParentID string
 }
 
 type Update struct {
 	ID   string
 	Name string
+	// ParentID moves the category when set; an empty string makes it
+	// top-level.
+	ParentID *string
+}
+
+// Detail is a category with its breadcrumb path, which runs from the
+// top-level category down to and including the category itself.
+type Detail struct {
+	Data Data
+	Path []Data
+}
+
+// Node is a category with its subcategories.
+type Node struct {
+	Data     Data
+	Children []Node
 }
@@ -2,12 +2,24 @@ package category
 
 import (
 	"context"
+	"mini-ecommerce/internal/helper"
 )
 
 type Repository interface {
 	Create(ctx context.Context, data *Data) error
 	Find(ctx context.Context, id string) (Data, error)
-	FindAll(ctx context.Context) ([]Data, error)
+	FindAll(ctx context.Context, page helper.PageRequest) ([]Data, int, error)
+	// FindEvery returns all categories ordered by name, for building the tree.
+	FindEvery(ctx context.Context) ([]Data, error)
+	// FindPath returns the category and its ancestors, top-level first.
+	FindPath(ctx context.Context, id string) ([]Data, error)
+	// FindDescendantIds returns id and the ids of every category below it.
+	// It is empty when id does not exist.
+	FindDescendantIds(ctx context.Context, id string) ([]string, error)
+	// LockTree blocks other transactions from changing categories until the
+	// surrounding transaction ends, so a cycle check stays valid until the
+	// move it guards is written.
+	LockTree(ctx context.Context) error
 	Update(ctx context.Context, update *Update) error
 	Delete(ctx context.Context, id string) error
 }
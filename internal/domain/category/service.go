@@ -7,8 +7,11 @@ import (
 
 type Service interface {
 	Create(ctx context.Context, data *Data) *helper.AppError
-	Get(ctx context.Context, id string) (Data, *helper.AppError)
-	GetAll(ctx context.Context) ([]Data, *helper.AppError)
+	Get(ctx context.Context, id string) (Detail, *helper.AppError)
+	GetAll(ctx context.Context, page helper.PageRequest) ([]Data, helper.Pagination, *helper.AppError)
+	// GetTree returns the top-level categories with their subcategories
+	// nested below them.
+	GetTree(ctx context.Context) ([]Node, *helper.AppError)
 	Update(ctx context.Context, update *Update) *helper.AppError
 	Delete(ctx context.Context, id string) *helper.AppError
 }
@@ -0,0 +1,23 @@
+package idempotency
+
+import "time"
+
+// Record is what is remembered about one Idempotency-Key of one user.
+type Record struct {
+	UserID int
+	Key    string
+	// Fingerprint is the SHA-256 of the method, path and body of the request
+	// that first used the key.
+	Fingerprint string
+	// StatusCode and Body hold the response to replay. StatusCode is zero
+	// while the first request is still being processed.
+	StatusCode int
+	Body       []byte
+	ExpiresAt  time.Time
+	// LockedUntil is when an unfinished request loses its claim on the key.
+	LockedUntil time.Time
+}
+
+func (r Record) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,13 @@
+package idempotency
+
+import "context"
+
+type Repository interface {
+	// Reserve stores record unless an unexpired record with the same user and
+	// key exists that is completed or still locked. It returns the stored
+	// record and whether it was reserved by this call.
+	Reserve(ctx context.Context, record Record) (Record, bool, error)
+	Complete(ctx context.Context, userId int, key string, statusCode int, body []byte) error
+	Release(ctx context.Context, userId int, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,17 @@
+package idempotency
+
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	// Begin claims key for a request with the given fingerprint. A completed
+	// record in the result is a response to replay instead of handling the
+	// request again.
+	Begin(ctx context.Context, userId int, key string, fingerprint string) (Record, *helper.AppError)
+	Complete(ctx context.Context, userId int, key string, statusCode int, body []byte) *helper.AppError
+	// Release forgets a key whose request failed, so a retry runs again.
+	Release(ctx context.Context, userId int, key string) *helper.AppError
+	PurgeExpired(ctx context.Context) (int64, *helper.AppError)
+}
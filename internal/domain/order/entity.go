@@ -1,23 +1,49 @@
 package order
 
+import (
+	"mini-ecommerce/internal/domain/address"
+	"mini-ecommerce/internal/domain/shipment"
+	"mini-ecommerce/internal/money"
+)
+
 type Status string
 
 const (
 	StatusPending   Status = "pending"
 	StatusPaid      Status = "paid"
 	StatusCancelled Status = "cancelled"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusRefunded  Status = "refunded"
 )
 
+// Data is an order. TotalPrice is what the customer pays: Subtotal, the sum
+// of the lines, minus Discount, plus Shipping and Tax.
 type Data struct {
 	ID         int
 	UserID     int
-	TotalPrice float64
-	Status     Status
+	Subtotal   money.Money
+	Discount   money.Money
+	Shipping   money.Money
+	Tax        money.Money
+	TotalPrice money.Money
+	// Refunded is how much of TotalPrice was paid back through returns.
+	Refunded money.Money
+	// CouponCode is empty when no coupon was used.
+	CouponCode   string
+	FreeShipping bool
+	// Region is the region shipping and tax were priced for.
+	Region string
+	// ShippingAddress is a copy of the address the order ships to, taken
+	// when it was placed; nil only on orders placed before addresses were
+	// required.
+	ShippingAddress *address.Address
+	Status          Status
 }
 
 type Update struct {
 	ID         int
-	TotalPrice *float64
+	TotalPrice *money.Money
 	Status     *Status
 }
 
@@ -25,16 +51,35 @@ type Item struct {
 	ID        int
 	OrderID   int
 	ProductID string
-	Price     float64
+	VariantID string
+	SKU       string
+	Price     money.Money
 	Quantity  int
 }
 
+func (i Item) LineTotal() money.Money {
+	return i.Price.Mul(i.Quantity)
+}
+
 type Detail struct {
 	Data  Data
 	Items []Item
+	// Shipments carry the tracking timeline of the order.
+	Shipments []shipment.Shipment
+}
+
+// Checkout holds the choices of the customer placing an order. Both fields
+// are optional; without an AddressID the order ships to the customer's default
+// address, and a customer without one cannot place an order.
+type Checkout struct {
+	CouponCode string
+	AddressID  string
 }
 
+// NewItem is a line of an order being placed. VariantID may be left empty
+// when the product has a single variant.
 type NewItem struct {
 	ProductID string
+	VariantID string
 	Quantity  int
 }
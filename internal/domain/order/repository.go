@@ -1,13 +1,25 @@
 package order
 
-import "context"
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+)
 
 type Repository interface {
 	Create(ctx context.Context, data *Data) error
 	FindById(ctx context.Context, id int) (Data, error)
-	FindByUserId(ctx context.Context, userId int) ([]Data, error)
+	// FindByIdForUpdate locks the order row until the transaction ends, so
+	// status changes on the same order run one at a time.
+	FindByIdForUpdate(ctx context.Context, id int) (Data, error)
+	FindByUserId(ctx context.Context, userId int, page helper.PageRequest) ([]Data, int, error)
 	Update(ctx context.Context, update *Update) error
 	UpdateStatus(ctx context.Context, id int, status Status) error
+	// HasPendingPayment reports whether a charge for the order is still
+	// waiting on the payment gateway.
+	HasPendingPayment(ctx context.Context, id int) (bool, error)
+	// AddRefunded adds amount to what was refunded on the order.
+	AddRefunded(ctx context.Context, id int, amount money.Money) error
 	Delete(ctx context.Context, id int) error
 }
 
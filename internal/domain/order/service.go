@@ -2,13 +2,16 @@ package order
 
 import (
 	"context"
+	"mini-ecommerce/internal/domain/user"
 	"mini-ecommerce/internal/helper"
 )
 
 type Service interface {
-	Create(ctx context.Context, userId int, newItems []NewItem) (Detail, *helper.AppError)
-	Get(ctx context.Context, id int) (Detail, *helper.AppError)
-	GetByUserId(ctx context.Context, userId int) ([]Detail, *helper.AppError)
-	UpdateStatus(ctx context.Context, id int, status Status) *helper.AppError
-	Cancel(ctx context.Context, id int) *helper.AppError
+	// Create places an order for newItems, priced for the coupon and address
+	// of checkout.
+	Create(ctx context.Context, userId int, newItems []NewItem, checkout Checkout) (Detail, *helper.AppError)
+	Get(ctx context.Context, caller user.Caller, id int) (Detail, *helper.AppError)
+	GetByUserId(ctx context.Context, userId int, page helper.PageRequest) ([]Detail, helper.Pagination, *helper.AppError)
+	UpdateStatus(ctx context.Context, caller user.Caller, id int, status Status) *helper.AppError
+	Cancel(ctx context.Context, caller user.Caller, id int) *helper.AppError
 }
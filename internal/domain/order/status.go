@@ -0,0 +1,46 @@
+package order
+
+import (
+	"fmt"
+	"mini-ecommerce/internal/helper"
+)
+
+// transitions lists, for every known status, the statuses an order may move
+// to next. Statuses with no entry are terminal.
+var transitions = map[Status][]Status{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusDelivered, StatusRefunded},
+	StatusDelivered: {StatusRefunded},
+	StatusCancelled: nil,
+	StatusRefunded:  nil,
+}
+
+func (s Status) IsValid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition is the single check every status change goes through.
+// The returned error wraps helper.ErrOrderInvalidStatus or
+// helper.ErrOrderInvalidTransition and names both states.
+func ValidateTransition(current Status, next Status) error {
+	if !next.IsValid() {
+		return fmt.Errorf("%w: %q", helper.ErrOrderInvalidStatus, next)
+	}
+
+	if !current.CanTransitionTo(next) {
+		return fmt.Errorf("%w: cannot move order from %q to %q", helper.ErrOrderInvalidTransition, current, next)
+	}
+
+	return nil
+}
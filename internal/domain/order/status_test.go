@@ -0,0 +1,36 @@
+package order
+
+import (
+	"errors"
+	"mini-ecommerce/internal/helper"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		current Status
+		next    Status
+		want    error
+	}{
+		{current: StatusPending, next: StatusPaid},
+		{current: StatusPending, next: StatusCancelled},
+		{current: StatusPaid, next: StatusShipped},
+		{current: StatusShipped, next: StatusDelivered},
+		{current: StatusDelivered, next: StatusRefunded},
+		{current: StatusPending, next: StatusShipped, want: helper.ErrOrderInvalidTransition},
+		{current: StatusPaid, next: StatusCancelled, want: helper.ErrOrderInvalidTransition},
+		{current: StatusCancelled, next: StatusPaid, want: helper.ErrOrderInvalidTransition},
+		{current: StatusRefunded, next: StatusRefunded, want: helper.ErrOrderInvalidTransition},
+		{current: StatusPending, next: Status("lost"), want: helper.ErrOrderInvalidStatus},
+	}
+
+	for _, test := range tests {
+		err := ValidateTransition(test.current, test.next)
+		if test.want == nil && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", test.current, test.next, err)
+		}
+		if test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("%s -> %s: got %v, want %v", test.current, test.next, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package payment
+
+import (
+	"mini-ecommerce/internal/money"
+	"time"
+)
+
+type Method string
+
+const (
+	MethodTransfer Method = "transfer"
+	MethodEwallet  Method = "ewallet"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+)
+
+type Data struct {
+	ID        int
+	OrderID   int
+	Method    Method
+	Status    Status
+	Amount    money.Money
+	Reference string
+	PaidAt    *time.Time
+}
+
+type Update struct {
+	ID        int
+	Status    Status
+	Reference string
+	PaidAt    *time.Time
+}
+
+type Charge struct {
+	PaymentID int
+	OrderID   int
+	Method    Method
+	Amount    money.Money
+}
+
+type ChargeResult struct {
+	Status    Status
+	Reference string
+}
+
+// Refund pays Amount of a charge back. ChargeReference is the reference the
+// gateway gave the charge.
+type Refund struct {
+	RefundID        string
+	PaymentID       int
+	OrderID         int
+	ChargeReference string
+	Amount          money.Money
+}
+
+type RefundResult struct {
+	Status    Status
+	Reference string
+}
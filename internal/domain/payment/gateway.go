@@ -0,0 +1,16 @@
+package payment
+
+import "context"
+
+// Gateway is the boundary to the payment provider. Implementations must be
+// safe for concurrent use.
+//
+// Charges are idempotent on PaymentID and refunds on RefundID: repeating one
+// returns the outcome of the first instead of moving money again. A declined request is reported through
+// the result status. An error means the outcome is unknown, as after a
+// timeout, and the request must be repeated to learn it.
+type Gateway interface {
+	Charge(ctx context.Context, charge Charge) (ChargeResult, error)
+	// Refund pays back part or all of a successful charge.
+	Refund(ctx context.Context, refund Refund) (RefundResult, error)
+}
@@ -0,0 +1,16 @@
+package payment
+
+import (
+	"context"
+	"time"
+)
+
+type Repository interface {
+	Create(ctx context.Context, data *Data) error
+	FindById(ctx context.Context, id int) (Data, error)
+	FindByOrderId(ctx context.Context, orderId int) ([]Data, error)
+	// FindPendingBefore lists payments still pending that were created
+	// before the given time, oldest first.
+	FindPendingBefore(ctx context.Context, before time.Time) ([]Data, error)
+	Update(ctx context.Context, update *Update) error
+}
@@ -0,0 +1,16 @@
+package payment
+
+import (
+	"context"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	Create(ctx context.Context, caller user.Caller, orderId int, method Method) (Data, *helper.AppError)
+	Get(ctx context.Context, caller user.Caller, id int) (Data, *helper.AppError)
+	GetByOrderId(ctx context.Context, caller user.Caller, orderId int) ([]Data, *helper.AppError)
+	// Reconcile settles payments left pending by a gateway that could not
+	// report how the charge ended.
+	Reconcile(ctx context.Context) (int, *helper.AppError)
+}
@@ -0,0 +1,28 @@
+package pricing
+
+import "mini-ecommerce/internal/money"
+
+// ShippingRate charges Fee for orders weighing at least MinWeightGrams. An
+// empty Region applies to every region.
+type ShippingRate struct {
+	ID             string
+	Region         string
+	MinWeightGrams int
+	Fee            money.Money
+}
+
+// TaxRate taxes products at BasisPoints, so 1100 is 11%. An empty Region or
+// CategoryID matches any; a category includes its subcategories.
+type TaxRate struct {
+	ID          string
+	Region      string
+	CategoryID  string
+	BasisPoints int64
+}
+
+// Line is an order line being taxed. Total is after its share of the
+// discount.
+type Line struct {
+	ProductID string
+	Total     money.Money
+}
@@ -0,0 +1,21 @@
+package pricing
+
+import "context"
+
+type ShippingRateRepository interface {
+	Create(ctx context.Context, rate *ShippingRate) error
+	FindAll(ctx context.Context) ([]ShippingRate, error)
+	// FindForWeight returns the rate an order of weightGrams priced in
+	// currency ships at in region, or helper.ErrShippingRateNotFound.
+	FindForWeight(ctx context.Context, region string, currency string, weightGrams int) (ShippingRate, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type TaxRateRepository interface {
+	Create(ctx context.Context, rate *TaxRate) error
+	FindAll(ctx context.Context) ([]TaxRate, error)
+	// FindForProducts returns the basis points each of productIds is taxed at
+	// in region. Products without a matching rate are left out.
+	FindForProducts(ctx context.Context, region string, productIds []string) (map[string]int64, error)
+	Delete(ctx context.Context, id string) error
+}
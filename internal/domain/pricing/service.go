@@ -0,0 +1,23 @@
+package pricing
+
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+)
+
+type Service interface {
+	CreateShippingRate(ctx context.Context, rate *ShippingRate) *helper.AppError
+	GetShippingRates(ctx context.Context) ([]ShippingRate, *helper.AppError)
+	DeleteShippingRate(ctx context.Context, id string) *helper.AppError
+	CreateTaxRate(ctx context.Context, rate *TaxRate) *helper.AppError
+	GetTaxRates(ctx context.Context) ([]TaxRate, *helper.AppError)
+	DeleteTaxRate(ctx context.Context, id string) *helper.AppError
+	// Shipping returns the fee for an order of weightGrams in region, priced
+	// in currency. It fails with helper.ErrShippingRateNotFound when no rate
+	// matches.
+	Shipping(ctx context.Context, region string, currency string, weightGrams int) (money.Money, *helper.AppError)
+	// Tax returns the tax on lines in region, which must share a currency.
+	// Lines without a matching rate are not taxed.
+	Tax(ctx context.Context, region string, lines []Line) (money.Money, *helper.AppError)
+}
@@ -1,12 +1,24 @@
 package product
 
+import (
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"strings"
+	"unicode"
+)
+
 type Data struct {
 	ID          string
 	CategoryID  string
 	Name        string
 	Description string
-	Price       float64
-	Stock       int
+	Price       money.Money
+	// WeightGrams is the shipping weight of one unit.
+	WeightGrams int
+	// Stock is the total over all variants.
+	Stock int
+	// PrimaryImage is nil for a product without images.
+	PrimaryImage *Image
 }
 
 type Update struct {
@@ -14,6 +26,102 @@ type Update struct {
 	CategoryID  *string
 	Name        *string
 	Description *string
-	Price       *float64
-	Stock       *int
+	Price       *money.Money
+	WeightGrams *int
+	// Stock can only be set on a product with a single variant.
+	Stock *int
+}
+
+// Variant is one sellable combination of options of a product, such as size M
+// in red. Every product has at least one, and stock is kept per variant.
+type Variant struct {
+	ID        string
+	ProductID string
+	SKU       string
+	Options   map[string]string
+	// Price is the variant's own price when PriceOverride is set and the
+	// product price otherwise.
+	Price         money.Money
+	PriceOverride bool
+	Stock         int
+	// WeightGrams is the product's weight; variants do not override it.
+	WeightGrams int
+}
+
+type VariantUpdate struct {
+	ID        string
+	ProductID string
+	SKU       *string
+	Options   map[string]string
+	// Price overrides the product price, in the product currency.
+	Price *int64
+	Stock *int
+}
+
+// Image is a product photo. Key and ThumbnailKey locate the files in blob
+// storage; the URLs are filled in by the service. Images are shown in
+// ascending Position, and exactly one image of a product is Primary.
+type Image struct {
+	ID           string
+	ProductID    string
+	Key          string
+	ThumbnailKey string
+	ContentType  string
+	Width        int
+	Height       int
+	Position     int
+	Primary      bool
+	URL          string
+	ThumbnailURL string
+}
+
+type Detail struct {
+	Data     Data
+	Variants []Variant
+	Images   []Image
+}
+
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByPrice     SortField = "price"
+	SortByName      SortField = "name"
+)
+
+type Filter struct {
+	CategoryID string
+	// IncludeDescendants widens CategoryID to every category below it.
+	IncludeDescendants bool
+	// CategoryIDs, when not nil, replaces CategoryID. The service fills it
+	// in from IncludeDescendants.
+	CategoryIDs []string
+	// MinPrice and MaxPrice are in minor units. They match a product when one
+	// of its variants, at its effective price, falls between them. Sorting by
+	// price orders products by their lowest variant price.
+	MinPrice   *int64
+	MaxPrice   *int64
+	InStock    bool
+	Search     string
+	SortBy     SortField
+	Descending bool
+	Page       helper.PageRequest
+}
+
+// SearchResult is a product matched by full-text or fuzzy search. The
+// highlights are HTML: the product text is escaped and matched terms are
+// wrapped in <mark> tags.
+type SearchResult struct {
+	Data          Data
+	Rank          float64
+	NameHighlight string
+	Snippet       string
+}
+
+// SearchTerms splits a search query into lower-case words of letters and
+// digits. A query without any is not searchable.
+func SearchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
 }
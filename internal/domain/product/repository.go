@@ -2,13 +2,42 @@ package product
 
 import (
 	"context"
+	"mini-ecommerce/internal/helper"
 )
 
 type Repository interface {
 	Create(ctx context.Context, data *Data) error
 	Find(ctx context.Context, id string) (Data, error)
-	FindAll(ctx context.Context) ([]Data, error)
+	// Lock row locks the product until the transaction ends, so changes to
+	// its set of variants run one at a time.
+	Lock(ctx context.Context, id string) error
+	FindAll(ctx context.Context, filter Filter) ([]Data, int, error)
+	Search(ctx context.Context, query string, page helper.PageRequest) ([]SearchResult, int, error)
 	Update(ctx context.Context, update *Update) error
+	Delete(ctx context.Context, id string) error
+}
+
+type VariantRepository interface {
+	Create(ctx context.Context, variant *Variant) error
+	FindById(ctx context.Context, id string) (Variant, error)
+	FindByProductId(ctx context.Context, productId string) ([]Variant, error)
+	// FindForUpdate returns the variants with the given ids, row locked until
+	// the surrounding transaction ends. Unknown ids are left out.
+	FindForUpdate(ctx context.Context, ids []string) ([]Variant, error)
+	Update(ctx context.Context, update *VariantUpdate) error
 	UpdateStock(ctx context.Context, id string, quantity int) error
+	IncreaseStock(ctx context.Context, id string, quantity int) error
+	Delete(ctx context.Context, id string) error
+}
+
+type ImageRepository interface {
+	// Create appends the image to the product's images, making it primary
+	// when it is the first one.
+	Create(ctx context.Context, image *Image) error
+	FindById(ctx context.Context, id string) (Image, error)
+	FindByProductId(ctx context.Context, productId string) ([]Image, error)
+	SetPrimary(ctx context.Context, productId string, id string) error
+	// Reorder sets each image's position to its index in ids.
+	Reorder(ctx context.Context, productId string, ids []string) error
 	Delete(ctx context.Context, id string) error
 }
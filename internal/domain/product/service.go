@@ -6,9 +6,25 @@ import (
 )
 
 type Service interface {
-	Create(ctx context.Context, data *Data) *helper.AppError
-	Get(ctx context.Context, id string) (Data, *helper.AppError)
-	GetAll(ctx context.Context) ([]Data, *helper.AppError)
+	// Create stores the product with its variants. A product created without
+	// variants gets a single one carrying Data.Stock.
+	Create(ctx context.Context, detail *Detail) *helper.AppError
+	Get(ctx context.Context, id string) (Detail, *helper.AppError)
+	GetAll(ctx context.Context, filter Filter) ([]Data, helper.Pagination, *helper.AppError)
+	Search(ctx context.Context, query string, page helper.PageRequest) ([]SearchResult, helper.Pagination, *helper.AppError)
 	Update(ctx context.Context, update *Update) *helper.AppError
 	Delete(ctx context.Context, id string) *helper.AppError
+	AddVariant(ctx context.Context, variant *Variant) *helper.AppError
+	UpdateVariant(ctx context.Context, update VariantUpdate) (Variant, *helper.AppError)
+	DeleteVariant(ctx context.Context, productId string, variantId string) *helper.AppError
+}
+
+type ImageService interface {
+	// Upload stores data, whose format is sniffed rather than trusted, along
+	// with a thumbnail and appends it to the product's images.
+	Upload(ctx context.Context, productId string, data []byte) (Image, *helper.AppError)
+	SetPrimary(ctx context.Context, productId string, imageId string) *helper.AppError
+	// Reorder takes every image id of the product in the new display order.
+	Reorder(ctx context.Context, productId string, imageIds []string) ([]Image, *helper.AppError)
+	Delete(ctx context.Context, productId string, imageId string) *helper.AppError
 }
@@ -0,0 +1,67 @@
+package promotion
+
+import (
+	"mini-ecommerce/internal/money"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindPercentage   Kind = "percentage"
+	KindFixedAmount  Kind = "fixed_amount"
+	KindFreeShipping Kind = "free_shipping"
+)
+
+// Coupon is a discount customers unlock with its code. A coupon with product
+// or category ids only discounts matching order lines; a category includes its
+// subcategories. Without either it applies to the whole order.
+type Coupon struct {
+	ID   string
+	Code string
+	Kind Kind
+	// PercentOff is in basis points, so 1500 is 15%. Percentage coupons only.
+	PercentOff int64
+	// AmountOff is taken off the matching lines. Fixed amount coupons only.
+	AmountOff money.Money
+	// MinOrder is compared with the order subtotal before any discount.
+	MinOrder money.Money
+	// StartsAt and EndsAt bound when the coupon can be used; nil is open.
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	// UsageLimit and PerUserLimit cap redemptions in total and per customer;
+	// zero is unlimited.
+	UsageLimit   int
+	PerUserLimit int
+	ProductIDs   []string
+	CategoryIDs  []string
+	Active       bool
+}
+
+// Line is an order line a coupon is checked against.
+type Line struct {
+	ProductID string
+	Total     money.Money
+}
+
+// Discount is what a coupon takes off an order. Amount is zero for free
+// shipping coupons.
+type Discount struct {
+	CouponID     string
+	Code         string
+	Amount       money.Money
+	FreeShipping bool
+	// Shares splits Amount over the lines it was applied to, in their order.
+	// Lines outside the coupon scope get none.
+	Shares []money.Money
+}
+
+// Redemption records that an order used a coupon, counting towards its limits
+// until the order is cancelled.
+type Redemption struct {
+	ID       string
+	CouponID string
+	UserID   int
+	OrderID  int
+	Discount money.Money
+}
@@ -0,0 +1,27 @@
+package promotion
+
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+)
+
+type Repository interface {
+	// Create stores the coupon together with its product and category scope.
+	Create(ctx context.Context, coupon *Coupon) error
+	FindById(ctx context.Context, id string) (Coupon, error)
+	// FindByCodeForUpdate row locks the coupon until the surrounding
+	// transaction ends, so its usage limits hold under concurrent orders.
+	FindByCodeForUpdate(ctx context.Context, code string) (Coupon, error)
+	FindAll(ctx context.Context, page helper.PageRequest) ([]Coupon, int, error)
+	// FindEligibleProductIds returns the productIds within the coupon's scope.
+	FindEligibleProductIds(ctx context.Context, couponId string, productIds []string) ([]string, error)
+	Deactivate(ctx context.Context, id string) error
+}
+
+type RedemptionRepository interface {
+	Create(ctx context.Context, redemption *Redemption) error
+	// Count returns how often the coupon was redeemed in total and by userId.
+	Count(ctx context.Context, couponId string, userId int) (int, int, error)
+	// DeleteByOrderId releases the redemption of an order, if it has one.
+	DeleteByOrderId(ctx context.Context, orderId int) error
+}
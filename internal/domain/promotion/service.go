@@ -0,0 +1,21 @@
+package promotion
+
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	Create(ctx context.Context, coupon *Coupon) *helper.AppError
+	Get(ctx context.Context, id string) (Coupon, *helper.AppError)
+	GetAll(ctx context.Context, page helper.PageRequest) ([]Coupon, helper.Pagination, *helper.AppError)
+	// Deactivate stops the coupon from being used; past redemptions stay.
+	Deactivate(ctx context.Context, id string) *helper.AppError
+	// Apply checks that userId may use code on an order with lines and
+	// computes the discount. It locks the coupon, so it must run in the same
+	// transaction as the Redeem that follows.
+	Apply(ctx context.Context, userId int, code string, lines []Line) (Discount, *helper.AppError)
+	Redeem(ctx context.Context, userId int, orderId int, discount Discount) *helper.AppError
+	// Release gives the coupon use of a cancelled order back.
+	Release(ctx context.Context, orderId int) *helper.AppError
+}
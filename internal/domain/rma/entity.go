@@ -0,0 +1,47 @@
+package rma
+
+import (
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/money"
+)
+
+type Status string
+
+const (
+	StatusRequested Status = "requested"
+	StatusApproved  Status = "approved"
+	StatusRejected  Status = "rejected"
+	StatusReceived  Status = "received"
+	StatusRefunded  Status = "refunded"
+)
+
+// Return is a customer's request to send Items of an order back.
+type Return struct {
+	ID      string
+	OrderID int
+	UserID  int
+	Status  Status
+	Reason  string
+	// ResolutionNote is the admin's note on approving or rejecting.
+	ResolutionNote string
+	// Restocked is set when the items went back into stock on receipt.
+	Restocked bool
+	Refunded  money.Money
+	Items     []Item
+}
+
+// Item is the quantity of an order item being returned.
+type Item struct {
+	OrderItemID int
+	Quantity    int
+}
+
+// Refund is an attempt to pay a return back through the payment gateway.
+type Refund struct {
+	ID        string
+	ReturnID  string
+	PaymentID int
+	Amount    money.Money
+	Status    payment.Status
+	Reference string
+}
@@ -0,0 +1,40 @@
+package rma
+
+import (
+	"context"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"time"
+)
+
+type Repository interface {
+	// LockOrder row locks the order until the surrounding transaction ends,
+	// so concurrent returns cannot return an item twice.
+	LockOrder(ctx context.Context, orderId int) error
+	// Create stores the return with its items.
+	Create(ctx context.Context, data *Return) error
+	FindById(ctx context.Context, id string) (Return, error)
+	FindByOrderId(ctx context.Context, orderId int) ([]Return, error)
+	FindAll(ctx context.Context, page helper.PageRequest) ([]Return, int, error)
+	// ReturnedQuantities returns how much of each order item is in a return
+	// that was not rejected, keyed by order item id.
+	ReturnedQuantities(ctx context.Context, orderId int) (map[int]int, error)
+	// UpdateStatus moves the return to status and sets its resolution note
+	// unless note is empty.
+	UpdateStatus(ctx context.Context, id string, status Status, note string) error
+	SetRestocked(ctx context.Context, id string) error
+	// AddRefunded adds amount to what was refunded on the return.
+	AddRefunded(ctx context.Context, id string, amount money.Money) error
+}
+
+type RefundRepository interface {
+	Create(ctx context.Context, refund *Refund) error
+	FindById(ctx context.Context, id string) (Refund, error)
+	// FindPendingBefore lists refunds still pending that were created before
+	// the given time, oldest first.
+	FindPendingBefore(ctx context.Context, before time.Time) ([]Refund, error)
+	// HasPending reports whether a refund of the return is still waiting on
+	// the payment gateway.
+	HasPending(ctx context.Context, returnId string) (bool, error)
+	Update(ctx context.Context, refund *Refund) error
+}
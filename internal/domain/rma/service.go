@@ -0,0 +1,30 @@
+package rma
+
+import (
+	"context"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	// Request files a return for items of a paid, shipped or delivered order.
+	Request(ctx context.Context, caller user.Caller, data *Return) *helper.AppError
+	Get(ctx context.Context, caller user.Caller, id string) (Return, *helper.AppError)
+	GetByOrderId(ctx context.Context, caller user.Caller, orderId int) ([]Return, *helper.AppError)
+	GetAll(ctx context.Context, page helper.PageRequest) ([]Return, helper.Pagination, *helper.AppError)
+	Approve(ctx context.Context, caller user.Caller, id string, note string) (Return, *helper.AppError)
+	Reject(ctx context.Context, caller user.Caller, id string, note string) (Return, *helper.AppError)
+	// Receive records that the goods arrived back and, if restock is set, puts
+	// them back into stock.
+	Receive(ctx context.Context, caller user.Caller, id string, restock bool) (Return, *helper.AppError)
+	// Refund pays the return back through the payment gateway: amount, in
+	// minor units of the order currency, or what is left of the value of the
+	// returned items when it is nil. The return stays received until its
+	// whole value is refunded, so a partial refund can be followed by more.
+	// The order moves to refunded once every item of it came back in a
+	// refunded return.
+	Refund(ctx context.Context, caller user.Caller, id string, amount *int64) (Return, Refund, *helper.AppError)
+	// Reconcile settles refunds left pending by a gateway that could not
+	// report how the refund ended.
+	Reconcile(ctx context.Context) (int, *helper.AppError)
+}
@@ -0,0 +1,35 @@
+package rma
+
+import (
+	"fmt"
+	"mini-ecommerce/internal/helper"
+)
+
+// transitions lists, for every status, the statuses a return may move to
+// next. Statuses with no entry are terminal.
+var transitions = map[Status][]Status{
+	StatusRequested: {StatusApproved, StatusRejected},
+	StatusApproved:  {StatusReceived},
+	StatusReceived:  {StatusRefunded},
+	StatusRejected:  nil,
+	StatusRefunded:  nil,
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition wraps helper.ErrReturnInvalidTransition and names both
+// states.
+func ValidateTransition(current Status, next Status) error {
+	if !current.CanTransitionTo(next) {
+		return fmt.Errorf("%w: cannot move return from %q to %q", helper.ErrReturnInvalidTransition, current, next)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package rma
+
+import (
+	"errors"
+	"mini-ecommerce/internal/helper"
+	"testing"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		current Status
+		next    Status
+		allowed bool
+	}{
+		{current: StatusRequested, next: StatusApproved, allowed: true},
+		{current: StatusRequested, next: StatusRejected, allowed: true},
+		{current: StatusApproved, next: StatusReceived, allowed: true},
+		{current: StatusReceived, next: StatusRefunded, allowed: true},
+		{current: StatusRequested, next: StatusReceived},
+		{current: StatusApproved, next: StatusRejected},
+		{current: StatusRejected, next: StatusApproved},
+		{current: StatusRefunded, next: StatusRefunded},
+	}
+
+	for _, test := range tests {
+		err := ValidateTransition(test.current, test.next)
+		if test.allowed && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", test.current, test.next, err)
+		}
+		if !test.allowed && !errors.Is(err, helper.ErrReturnInvalidTransition) {
+			t.Errorf("%s -> %s: got %v, want %v", test.current, test.next, err, helper.ErrReturnInvalidTransition)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package shipment
+
+import (
+	"mini-ecommerce/internal/helper"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusShipped   Status = "shipped"
+	StatusInTransit Status = "in_transit"
+	StatusDelivered Status = "delivered"
+)
+
+// rank orders the statuses a shipment passes through.
+var rank = map[Status]int{
+	StatusShipped:   1,
+	StatusInTransit: 2,
+	StatusDelivered: 3,
+}
+
+func (s Status) IsValid() bool {
+	_, ok := rank[s]
+	return ok
+}
+
+// ValidateProgress checks that a shipment in current may record an event of
+// next. Shipments only move forward, though in_transit may repeat to track a
+// parcel between hubs.
+func ValidateProgress(current Status, next Status) error {
+	if !next.IsValid() || next == StatusShipped {
+		return helper.ErrShipmentInvalidStatus
+	}
+
+	if rank[next] < rank[current] || (next == current && next != StatusInTransit) {
+		return helper.ErrShipmentInvalidStatus
+	}
+
+	return nil
+}
+
+// Shipment carries Items of an order to the customer. Events is its tracking
+// timeline, oldest first.
+type Shipment struct {
+	ID             string
+	OrderID        int
+	Carrier        string
+	TrackingNumber string
+	Status         Status
+	ShippedAt      time.Time
+	DeliveredAt    *time.Time
+	Items          []Item
+	Events         []Event
+}
+
+// Item is the quantity of an order item in a shipment.
+type Item struct {
+	OrderItemID int
+	Quantity    int
+}
+
+type Event struct {
+	ID          string
+	ShipmentID  string
+	Status      Status
+	Description string
+	Location    string
+	OccurredAt  time.Time
+}
+
+// Update corrects the carrier or tracking number of a shipment.
+type Update struct {
+	ID             string
+	Carrier        *string
+	TrackingNumber *string
+}
@@ -0,0 +1,35 @@
+package shipment
+
+import (
+	"errors"
+	"mini-ecommerce/internal/helper"
+	"testing"
+)
+
+func TestValidateProgress(t *testing.T) {
+	tests := []struct {
+		current Status
+		next    Status
+		allowed bool
+	}{
+		{current: StatusShipped, next: StatusInTransit, allowed: true},
+		{current: StatusShipped, next: StatusDelivered, allowed: true},
+		{current: StatusInTransit, next: StatusInTransit, allowed: true},
+		{current: StatusInTransit, next: StatusDelivered, allowed: true},
+		{current: StatusShipped, next: StatusShipped},
+		{current: StatusInTransit, next: StatusShipped},
+		{current: StatusDelivered, next: StatusInTransit},
+		{current: StatusDelivered, next: StatusDelivered},
+		{current: StatusShipped, next: Status("lost")},
+	}
+
+	for _, test := range tests {
+		err := ValidateProgress(test.current, test.next)
+		if test.allowed && err != nil {
+			t.Errorf("%s -> %s: unexpected error %v", test.current, test.next, err)
+		}
+		if !test.allowed && !errors.Is(err, helper.ErrShipmentInvalidStatus) {
+			t.Errorf("%s -> %s: got %v, want %v", test.current, test.next, err, helper.ErrShipmentInvalidStatus)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package shipment
+
+import "context"
+
+type Repository interface {
+	// LockOrder row locks the order until the surrounding transaction ends,
+	// so concurrent shipments cannot ship an item twice.
+	LockOrder(ctx context.Context, orderId int) error
+	// Create stores the shipment with its items.
+	Create(ctx context.Context, shipment *Shipment) error
+	// FindById returns the shipment with its items and events.
+	FindById(ctx context.Context, id string) (Shipment, error)
+	// FindByOrderId returns the shipments of an order with their items and
+	// events, oldest first.
+	FindByOrderId(ctx context.Context, orderId int) ([]Shipment, error)
+	// ShippedQuantities returns how much of each order item is in a shipment
+	// already, keyed by order item id.
+	ShippedQuantities(ctx context.Context, orderId int) (map[int]int, error)
+	Update(ctx context.Context, update *Update) error
+	// CreateEvent adds an event to the timeline and moves the shipment to its
+	// status; a delivered event also sets DeliveredAt.
+	CreateEvent(ctx context.Context, event *Event) error
+}
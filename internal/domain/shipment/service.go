@@ -0,0 +1,20 @@
+package shipment
+
+import (
+	"context"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+)
+
+type Service interface {
+	// Create ships the items of a paid or shipped order; without items it
+	// ships everything not shipped yet. The first shipment moves the order to
+	// shipped.
+	Create(ctx context.Context, caller user.Caller, shipment *Shipment) *helper.AppError
+	Get(ctx context.Context, caller user.Caller, id string) (Shipment, *helper.AppError)
+	GetByOrderId(ctx context.Context, caller user.Caller, orderId int) ([]Shipment, *helper.AppError)
+	Update(ctx context.Context, caller user.Caller, update *Update) (Shipment, *helper.AppError)
+	// AddEvent records tracking progress. Once every item of the order is
+	// shipped and every shipment delivered, the order moves to delivered.
+	AddEvent(ctx context.Context, caller user.Caller, event *Event) (Shipment, *helper.AppError)
+}
@@ -1,10 +1,34 @@
 package user
 
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleCustomer Role = "customer"
+)
+
+// Caller identifies the authenticated user behind a request.
+type Caller struct {
+	ID   int
+	Role Role
+}
+
+func (c Caller) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+// Owns reports whether the caller may act on a resource belonging to userId.
+// Admins may act on every resource.
+func (c Caller) Owns(userId int) bool {
+	return c.IsAdmin() || c.ID == userId
+}
+
 type Data struct {
 	ID       int
 	Name     string
 	Email    string
 	Password string
+	Role     Role
 }
 
 type Update struct {
@@ -6,8 +6,11 @@ import (
 
 type Repository interface {
 	Create(ctx context.Context, data *Data) error
-	FindByEmail(ctx context.Context, login Login) (Data, string, error)
+	FindByEmail(ctx context.Context, email string) (Data, error)
 	FindById(ctx context.Context, id int) (Data, error)
 	Update(ctx context.Context, update *Update) error
 	Delete(ctx context.Context, id int) error
+	CountByRole(ctx context.Context, role Role) (int, error)
+	UpdateRoleByEmail(ctx context.Context, email string, role Role) error
+	UpdateRole(ctx context.Context, id int, role Role) error
 }
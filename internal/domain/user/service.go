@@ -7,7 +7,10 @@ import (
 
 type Service interface {
 	Create(ctx context.Context, data *Data) *helper.AppError
-	GetByEmail(ctx context.Context, login Login) (Data, string, *helper.AppError)
 	Update(ctx context.Context, update *Update) *helper.AppError
 	Delete(ctx context.Context, id int) *helper.AppError
+	BootstrapAdmin(ctx context.Context, data *Data) *helper.AppError
+	// UpdateRole gives user id role. The user's tokens keep their old role
+	// until they are refreshed.
+	UpdateRole(ctx context.Context, id int, role Role) *helper.AppError
 }
@@ -0,0 +1,76 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mini-ecommerce/internal/domain/payment"
+	"sync"
+	"sync/atomic"
+)
+
+// fakePaymentGateway settles every charge in-process so the payment flow can
+// be exercised end to end without an outside provider. Charges with an amount
+// of zero or less, or for a method listed in declined, are reported as failed.
+// Outcomes are kept in memory so a repeated charge returns the first one.
+type fakePaymentGateway struct {
+	seq      atomic.Int64
+	declined map[payment.Method]bool
+	charges  sync.Map
+	refunds  sync.Map
+}
+
+func NewFakePayment(declined ...payment.Method) payment.Gateway {
+	fake := &fakePaymentGateway{declined: map[payment.Method]bool{}}
+	for _, method := range declined {
+		fake.declined[method] = true
+	}
+	return fake
+}
+
+func (f *fakePaymentGateway) Charge(ctx context.Context, charge payment.Charge) (payment.ChargeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return payment.ChargeResult{}, err
+	}
+
+	if charge.PaymentID == 0 {
+		return payment.ChargeResult{}, errors.New("Payment id is required")
+	}
+
+	result := payment.ChargeResult{
+		Status:    payment.StatusSuccess,
+		Reference: fmt.Sprintf("FAKE-%d-%d", charge.OrderID, f.seq.Add(1)),
+	}
+	if charge.Amount.Amount <= 0 || f.declined[charge.Method] {
+		result.Status = payment.StatusFailed
+	}
+
+	first, _ := f.charges.LoadOrStore(charge.PaymentID, result)
+	return first.(payment.ChargeResult), nil
+}
+
+// Refund settles like Charge: refunds of zero or less are reported as failed.
+func (f *fakePaymentGateway) Refund(ctx context.Context, refund payment.Refund) (payment.RefundResult, error) {
+	if err := ctx.Err(); err != nil {
+		return payment.RefundResult{}, err
+	}
+
+	if refund.PaymentID == 0 {
+		return payment.RefundResult{}, errors.New("Payment id is required")
+	}
+
+	if refund.RefundID == "" {
+		return payment.RefundResult{}, errors.New("Refund id is required")
+	}
+
+	result := payment.RefundResult{
+		Status:    payment.StatusSuccess,
+		Reference: fmt.Sprintf("FAKE-REFUND-%d-%d", refund.OrderID, f.seq.Add(1)),
+	}
+	if refund.Amount.Amount <= 0 {
+		result.Status = payment.StatusFailed
+	}
+
+	first, _ := f.refunds.LoadOrStore(refund.RefundID, result)
+	return first.(payment.RefundResult), nil
+}
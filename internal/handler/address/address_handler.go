@@ -0,0 +1,155 @@
+package address
+
+import (
+	"mini-ecommerce/internal/domain/address"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler struct {
+	addressService address.Service
+}
+
+func NewHandler(addressService address.Service) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+func (h *AddressHandler) Create(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	data := address.Data{
+		UserID: userId,
+		Address: address.Address{
+			Recipient:  req.Recipient,
+			Phone:      req.Phone,
+			Line1:      req.Line1,
+			Line2:      req.Line2,
+			City:       req.City,
+			PostalCode: req.PostalCode,
+			Region:     req.Region,
+			Country:    req.Country,
+		},
+		Default: req.Default,
+	}
+	if appErr := h.addressService.Create(c.Request.Context(), &data); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Create Address",
+		newResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *AddressHandler) Get(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	data, appErr := h.addressService.Get(c.Request.Context(), userId, c.Param("id"))
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Get Address",
+		newResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *AddressHandler) GetAll(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	addresses, appErr := h.addressService.GetAll(c.Request.Context(), userId)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	addressResponses := []Response{}
+	for _, data := range addresses {
+		addressResponses = append(addressResponses, newResponse(data))
+	}
+
+	status, res := response.Success(
+		"Success Get Addresses",
+		addressResponses,
+	)
+	c.JSON(status, res)
+}
+
+func (h *AddressHandler) Update(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	var req UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	addressUpdate := address.Update{
+		ID:         c.Param("id"),
+		UserID:     userId,
+		Recipient:  req.Recipient,
+		Phone:      req.Phone,
+		Line1:      req.Line1,
+		Line2:      req.Line2,
+		City:       req.City,
+		PostalCode: req.PostalCode,
+		Region:     req.Region,
+		Country:    req.Country,
+		Default:    req.Default,
+	}
+	if appErr := h.addressService.Update(c.Request.Context(), &addressUpdate); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Update Address",
+		Response{
+			ID:         addressUpdate.ID,
+			Recipient:  *addressUpdate.Recipient,
+			Phone:      *addressUpdate.Phone,
+			Line1:      *addressUpdate.Line1,
+			Line2:      *addressUpdate.Line2,
+			City:       *addressUpdate.City,
+			PostalCode: *addressUpdate.PostalCode,
+			Region:     *addressUpdate.Region,
+			Country:    *addressUpdate.Country,
+			Default:    *addressUpdate.Default,
+		},
+	)
+	c.JSON(status, res)
+}
+
+func (h *AddressHandler) Delete(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	if appErr := h.addressService.Delete(c.Request.Context(), userId, c.Param("id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Delete Address")
+	c.JSON(status, res)
+}
@@ -0,0 +1,27 @@
+package address
+
+// CreateRequest takes region as the code shipping and tax rates are matched
+// on and country as an ISO 3166-1 alpha-2 code.
+type CreateRequest struct {
+	Recipient  string `json:"recipient" binding:"required,max=100"`
+	Phone      string `json:"phone" binding:"required,max=30"`
+	Line1      string `json:"line1" binding:"required,max=200"`
+	Line2      string `json:"line2" binding:"max=200"`
+	City       string `json:"city" binding:"required,max=100"`
+	PostalCode string `json:"postal_code" binding:"required,max=20"`
+	Region     string `json:"region" binding:"max=20"`
+	Country    string `json:"country" binding:"required,len=2"`
+	Default    bool   `json:"is_default"`
+}
+
+type UpdateRequest struct {
+	Recipient  *string `json:"recipient,omitempty" binding:"omitempty,min=1,max=100"`
+	Phone      *string `json:"phone,omitempty" binding:"omitempty,min=1,max=30"`
+	Line1      *string `json:"line1,omitempty" binding:"omitempty,min=1,max=200"`
+	Line2      *string `json:"line2,omitempty" binding:"omitempty,max=200"`
+	City       *string `json:"city,omitempty" binding:"omitempty,min=1,max=100"`
+	PostalCode *string `json:"postal_code,omitempty" binding:"omitempty,min=1,max=20"`
+	Region     *string `json:"region,omitempty" binding:"omitempty,max=20"`
+	Country    *string `json:"country,omitempty" binding:"omitempty,len=2"`
+	Default    *bool   `json:"is_default,omitempty"`
+}
@@ -0,0 +1,31 @@
+package address
+
+import "mini-ecommerce/internal/domain/address"
+
+type Response struct {
+	ID         string `json:"id"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Region     string `json:"region,omitempty"`
+	Country    string `json:"country"`
+	Default    bool   `json:"is_default"`
+}
+
+func newResponse(data address.Data) Response {
+	return Response{
+		ID:         data.ID,
+		Recipient:  data.Address.Recipient,
+		Phone:      data.Address.Phone,
+		Line1:      data.Address.Line1,
+		Line2:      data.Address.Line2,
+		City:       data.Address.City,
+		PostalCode: data.Address.PostalCode,
+		Region:     data.Address.Region,
+		Country:    data.Address.Country,
+		Default:    data.Default,
+	}
+}
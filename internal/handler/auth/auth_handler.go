@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"mini-ecommerce/internal/domain/auth"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	authService auth.Service
+}
+
+func NewHandler(authService auth.Service) *AuthHandler {
+	return &AuthHandler{authService: authService}
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	device := req.Device
+	if device == "" {
+		device = userAgentLabel(c.Request.UserAgent())
+	}
+
+	login := user.Login{
+		Email:    req.Email,
+		Password: req.Password,
+	}
+	userData, tokenPair, appErr := h.authService.Login(c.Request.Context(), login, device)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Login",
+		LoginResponse{
+			ID:                    userData.ID,
+			Name:                  userData.Name,
+			Email:                 userData.Email,
+			Role:                  userData.Role,
+			AccessToken:           tokenPair.AccessToken,
+			RefreshToken:          tokenPair.RefreshToken,
+			RefreshTokenExpiresAt: tokenPair.RefreshTokenExpiresAt,
+		},
+	)
+	c.JSON(status, res)
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	tokenPair, appErr := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Refresh Token",
+		TokenResponse{
+			AccessToken:           tokenPair.AccessToken,
+			RefreshToken:          tokenPair.RefreshToken,
+			RefreshTokenExpiresAt: tokenPair.RefreshTokenExpiresAt,
+		},
+	)
+	c.JSON(status, res)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	if appErr := h.authService.Logout(c.Request.Context(), req.RefreshToken); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Logout")
+	c.JSON(status, res)
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	if appErr := h.authService.LogoutAll(c.Request.Context(), userId); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Logout From All Devices")
+	c.JSON(status, res)
+}
+
+// userAgentLabel cuts a User-Agent used as the device label down to the
+// length the request's device field is limited to.
+func userAgentLabel(userAgent string) string {
+	if runes := []rune(userAgent); len(runes) > maxDeviceLength {
+		return string(runes[:maxDeviceLength])
+	}
+	return userAgent
+}
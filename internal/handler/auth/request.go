@@ -0,0 +1,18 @@
+package auth
+
+// maxDeviceLength matches the device binding below.
+const maxDeviceLength = 100
+
+type LoginRequest struct {
+	Email    string `json:"email" binding:"required,email,max=50"`
+	Password string `json:"password" binding:"required,min=4"`
+	Device   string `json:"device" binding:"omitempty,max=100"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"mini-ecommerce/internal/domain/user"
+	"time"
+)
+
+type LoginResponse struct {
+	ID                    int       `json:"id"`
+	Name                  string    `json:"name"`
+	Email                 string    `json:"email"`
+	Role                  user.Role `json:"role"`
+	AccessToken           string    `json:"access_token"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+type TokenResponse struct {
+	AccessToken           string    `json:"access_token"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
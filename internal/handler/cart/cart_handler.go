@@ -2,7 +2,10 @@ package cart
 
 import (
 	"errors"
+	"io"
 	"mini-ecommerce/internal/domain/cart"
+	orderDomain "mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/handler/order"
 	"mini-ecommerce/internal/helper"
 	"mini-ecommerce/internal/response"
 	"net/http"
@@ -32,7 +35,7 @@ func (h *CartHandler) AddItem(c *gin.Context) {
 		return
 	}
 
-	cartItem, appErr := h.cartService.AddItem(c.Request.Context(), userId, req.ProductId, req.Quantity)
+	cartItem, appErr := h.cartService.AddItem(c.Request.Context(), userId, req.ProductId, req.VariantId, req.Quantity)
 	if appErr != nil {
 		c.Error(appErr)
 		return
@@ -44,6 +47,7 @@ func (h *CartHandler) AddItem(c *gin.Context) {
 			ID:        cartItem.ID,
 			CartID:    cartItem.CartID,
 			ProductID: cartItem.ProductID,
+			VariantID: cartItem.VariantID,
 			Quantity:  cartItem.Quantity,
 		},
 	)
@@ -65,6 +69,7 @@ func (h *CartHandler) GetItems(c *gin.Context) {
 			ID:        cartItem.ID,
 			CartID:    cartItem.CartID,
 			ProductID: cartItem.ProductID,
+			VariantID: cartItem.VariantID,
 			Quantity:  cartItem.Quantity,
 		}
 		itemResponses = append(itemResponses, itemResponse)
@@ -134,3 +139,48 @@ func (h *CartHandler) DeleteItem(c *gin.Context) {
 	status, res := response.SuccessNoContent("Success Delete Cart Item")
 	c.JSON(status, res)
 }
+
+func (h *CartHandler) Checkout(c *gin.Context) {
+	userId := c.MustGet("user_id").(int)
+
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	orderDetail, appErr := h.cartService.Checkout(c.Request.Context(), userId, orderDomain.Checkout{
+		CouponCode: req.CouponCode,
+		AddressID:  req.AddressID,
+	})
+	if appErr != nil {
+		var checkoutErr *cart.CheckoutError
+		if errors.As(appErr.Err, &checkoutErr) {
+			issueResponses := []CheckoutIssueResponse{}
+			for _, issue := range checkoutErr.Issues {
+				issueResponses = append(issueResponses, CheckoutIssueResponse{
+					CartItemID: issue.CartItemID,
+					ProductID:  issue.ProductID,
+					VariantID:  issue.VariantID,
+					Quantity:   issue.Quantity,
+					Available:  issue.Available,
+					Reason:     issue.Reason.Error(),
+				})
+			}
+			appErr.Details = issueResponses
+		}
+
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Checkout Cart",
+		order.NewDetailResponse(orderDetail),
+	)
+	c.JSON(status, res)
+}
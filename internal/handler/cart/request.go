@@ -1,10 +1,18 @@
 package cart
 
 type AddItemRequest struct {
-	ProductId string `json:"product_id" binding:"required"`
+	ProductId string `json:"product_id" binding:"required_without=VariantId"`
+	VariantId string `json:"variant_id"`
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
+// CheckoutRequest is optional; checkout without a body uses no coupon and
+// ships to the default address.
+type CheckoutRequest struct {
+	CouponCode string `json:"coupon_code" binding:"omitempty,max=50"`
+	AddressID  string `json:"address_id"`
+}
+
 type UpdateItemRequest struct {
 	CartItemId int `json:"cart_item_id" binding:"required"`
 	Quantity   int `json:"quantity" binding:"required,min=1"`
@@ -4,5 +4,15 @@ type ItemResponse struct {
 	ID        int    `json:"id"`
 	CartID    int    `json:"cart_id"`
 	ProductID string `json:"product_id"`
+	VariantID string `json:"variant_id"`
 	Quantity  int    `json:"quantity"`
 }
+
+type CheckoutIssueResponse struct {
+	CartItemID int    `json:"cart_item_id"`
+	ProductID  string `json:"product_id"`
+	VariantID  string `json:"variant_id"`
+	Quantity   int    `json:"quantity"`
+	Available  int    `json:"available"`
+	Reason     string `json:"reason"`
+}
@@ -29,7 +29,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 		return
 	}
 
-	categoryData := category.Data{Name: req.Name}
+	categoryData := category.Data{Name: req.Name, ParentID: req.ParentID}
 	if appErr := h.categoryService.Create(c.Request.Context(), &categoryData); appErr != nil {
 		c.Error(appErr)
 		return
@@ -37,10 +37,7 @@ func (h *CategoryHandler) Create(c *gin.Context) {
 
 	status, res := response.Success(
 		"Success Create Category",
-		Response{
-			ID:   categoryData.ID,
-			Name: categoryData.Name,
-		},
+		newResponse(categoryData),
 	)
 	c.JSON(status, res)
 }
@@ -56,41 +53,67 @@ func (h *CategoryHandler) Get(c *gin.Context) {
 		return
 	}
 
-	categoryData, appErr := h.categoryService.Get(c.Request.Context(), id)
+	detail, appErr := h.categoryService.Get(c.Request.Context(), id)
 	if appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
+	detailResponse := DetailResponse{
+		Response:    newResponse(detail.Data),
+		Breadcrumbs: []Response{},
+	}
+	for _, ancestor := range detail.Path {
+		detailResponse.Breadcrumbs = append(detailResponse.Breadcrumbs, newResponse(ancestor))
+	}
+
 	status, res := response.Success(
 		"Success Get Category",
-		Response{
-			ID:   categoryData.ID,
-			Name: categoryData.Name,
-		},
+		detailResponse,
 	)
 	c.JSON(status, res)
 }
 
 func (h *CategoryHandler) GetAll(c *gin.Context) {
-	categories, appErr := h.categoryService.GetAll(c.Request.Context())
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Query Parameters",
+			err,
+		))
+		return
+	}
+
+	categories, pagination, appErr := h.categoryService.GetAll(c.Request.Context(), req.PageRequest())
 	if appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
-	var categoryResponses []Response
+	categoryResponses := []Response{}
 	for _, category := range categories {
-		response := Response{
-			ID:   category.ID,
-			Name: category.Name,
-		}
-		categoryResponses = append(categoryResponses, response)
+		categoryResponses = append(categoryResponses, newResponse(category))
 	}
 
-	status, res := response.Success(
+	status, res := response.SuccessPaginated(
 		"Success Get Categories",
 		categoryResponses,
+		pagination,
+	)
+	c.JSON(status, res)
+}
+
+func (h *CategoryHandler) GetTree(c *gin.Context) {
+	nodes, appErr := h.categoryService.GetTree(c.Request.Context())
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Get Category Tree",
+		newNodeResponses(nodes),
 	)
 	c.JSON(status, res)
 }
@@ -107,8 +130,9 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	}
 
 	categoryUpdate := category.Update{
-		ID:   req.ID,
-		Name: req.Name,
+		ID:       req.ID,
+		Name:     req.Name,
+		ParentID: req.ParentID,
 	}
 	if appErr := h.categoryService.Update(c.Request.Context(), &categoryUpdate); appErr != nil {
 		c.Error(appErr)
@@ -118,8 +142,9 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 	status, res := response.Success(
 		"Success Update Category",
 		Response{
-			ID:   categoryUpdate.ID,
-			Name: categoryUpdate.Name,
+			ID:       categoryUpdate.ID,
+			Name:     categoryUpdate.Name,
+			ParentID: *categoryUpdate.ParentID,
 		},
 	)
 	c.JSON(status, res)
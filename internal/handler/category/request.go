@@ -1,10 +1,19 @@
 package category
 
+import "mini-ecommerce/internal/helper"
+
 type CreateRequest struct {
-	Name string `json:"name" binding:"required,min=3,max=50"`
+	Name     string `json:"name" binding:"required,min=3,max=50"`
+	ParentID string `json:"parent_id"`
 }
 
 type UpdateRequest struct {
 	ID   string `json:"id" binding:"required"`
 	Name string `json:"name" binding:"required,min=3,max=50"`
+	// ParentID moves the category when present; "" makes it top-level.
+	ParentID *string `json:"parent_id"`
+}
+
+type ListRequest struct {
+	helper.PageQuery
 }
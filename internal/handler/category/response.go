@@ -1,6 +1,41 @@
 package category
 
+import "mini-ecommerce/internal/domain/category"
+
 type Response struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+// DetailResponse is a category with its breadcrumbs, which run from the
+// top-level category down to and including the category itself.
+type DetailResponse struct {
+	Response
+	Breadcrumbs []Response `json:"breadcrumbs"`
+}
+
+type NodeResponse struct {
+	Response
+	Children []NodeResponse `json:"children"`
+}
+
+func newResponse(categoryData category.Data) Response {
+	return Response{
+		ID:       categoryData.ID,
+		Name:     categoryData.Name,
+		ParentID: categoryData.ParentID,
+	}
+}
+
+func newNodeResponses(nodes []category.Node) []NodeResponse {
+	nodeResponses := []NodeResponse{}
+	for _, node := range nodes {
+		nodeResponses = append(nodeResponses, NodeResponse{
+			Response: newResponse(node.Data),
+			Children: newNodeResponses(node.Children),
+		})
+	}
+
+	return nodeResponses
 }
@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"log"
+	"mini-ecommerce/internal/buildinfo"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/response"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	checkTimeout = time.Second
+	// schemaRecheckInterval keeps /readyz cheap to poll: once the schema is
+	// known to be current it is only queried again after this long.
+	schemaRecheckInterval = 30 * time.Second
+)
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+type SchemaChecker interface {
+	EnsureLatest(ctx context.Context) error
+}
+
+type HealthHandler struct {
+	readiness       *helper.Readiness
+	db              Pinger
+	schema          SchemaChecker
+	schemaCheckedAt atomic.Int64
+	version         VersionResponse
+}
+
+// NewHandler builds the probe endpoints. schema may be nil to skip the
+// migration version check.
+func NewHandler(readiness *helper.Readiness, db Pinger, schema SchemaChecker) *HealthHandler {
+	info := buildinfo.Get()
+	return &HealthHandler{
+		readiness: readiness,
+		db:        db,
+		schema:    schema,
+		version: VersionResponse{
+			Commit:    info.Commit,
+			BuildTime: info.BuildTime,
+			GoVersion: info.GoVersion,
+		},
+	}
+}
+
+// Live reports that the process is up. It stays healthy while draining so the
+// orchestrator does not restart a pod that is shutting down on purpose.
+func (h *HealthHandler) Live(c *gin.Context) {
+	status, res := response.Success("Alive", nil)
+	c.JSON(status, res)
+}
+
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.readiness.IsDraining() {
+		status, res := response.Error(
+			"Not Ready",
+			ReadyResponse{Checks: map[string]string{"server": "shutting down"}},
+			http.StatusServiceUnavailable,
+		)
+		c.JSON(status, res)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), checkTimeout)
+	defer cancel()
+
+	checks := map[string]string{"database": "ok"}
+	ready := true
+
+	// The probe is unauthenticated, so failures are only detailed in the log;
+	// they can name hosts, users and queries.
+	if err := h.db.Ping(ctx); err != nil {
+		log.Printf("[HEALTH] database : %v", err)
+		checks["database"] = "unavailable"
+		ready = false
+	}
+
+	if h.schema != nil {
+		checks["schema"] = "ok"
+		if err := h.checkSchema(ctx); err != nil {
+			log.Printf("[HEALTH] schema : %v", err)
+			checks["schema"] = "unavailable"
+			ready = false
+		}
+	}
+
+	if !ready {
+		status, res := response.Error(
+			"Not Ready",
+			ReadyResponse{Checks: checks},
+			http.StatusServiceUnavailable,
+		)
+		c.JSON(status, res)
+		return
+	}
+
+	status, res := response.Success("Ready", ReadyResponse{Checks: checks})
+	c.JSON(status, res)
+}
+
+func (h *HealthHandler) Version(c *gin.Context) {
+	status, res := response.Success("Success Get Version", h.version)
+	c.JSON(status, res)
+}
+
+func (h *HealthHandler) checkSchema(ctx context.Context) error {
+	checkedAt := h.schemaCheckedAt.Load()
+	if checkedAt != 0 && time.Since(time.Unix(0, checkedAt)) < schemaRecheckInterval {
+		return nil
+	}
+
+	if err := h.schema.EnsureLatest(ctx); err != nil {
+		h.schemaCheckedAt.Store(0)
+		return err
+	}
+
+	h.schemaCheckedAt.Store(time.Now().UnixNano())
+	return nil
+}
@@ -0,0 +1,11 @@
+package health
+
+type ReadyResponse struct {
+	Checks map[string]string `json:"checks"`
+}
+
+type VersionResponse struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"mini-ecommerce/internal/domain/order"
 	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/middleware"
 	"mini-ecommerce/internal/response"
 	"net/http"
 	"strconv"
@@ -36,40 +37,24 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	for _, item := range req.Items {
 		newItem := order.NewItem{
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
 		}
 		newItems = append(newItems, newItem)
 	}
 
-	orderDetail, appErr := h.orderService.Create(c.Request.Context(), userId, newItems)
+	orderDetail, appErr := h.orderService.Create(c.Request.Context(), userId, newItems, order.Checkout{
+		CouponCode: req.CouponCode,
+		AddressID:  req.AddressID,
+	})
 	if appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
-	itemResponses := []ItemResponse{}
-	for _, item := range orderDetail.Items {
-		itemResponse := ItemResponse{
-			ID:        item.ID,
-			OrderID:   item.OrderID,
-			ProductID: item.ProductID,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-		}
-		itemResponses = append(itemResponses, itemResponse)
-	}
-
 	status, res := response.Success(
 		"Success Create Order",
-		DetailResponse{
-			Order: Response{
-				ID:         orderDetail.Data.ID,
-				UserID:     orderDetail.Data.UserID,
-				TotalPrice: orderDetail.Data.TotalPrice,
-				Status:     orderDetail.Data.Status,
-			},
-			Items: itemResponses,
-		},
+		NewDetailResponse(orderDetail),
 	)
 	c.JSON(status, res)
 }
@@ -96,35 +81,15 @@ func (h *OrderHandler) Get(c *gin.Context) {
 		return
 	}
 
-	orderDetail, appErr := h.orderService.Get(c.Request.Context(), orderId)
+	orderDetail, appErr := h.orderService.Get(c.Request.Context(), middleware.Caller(c), orderId)
 	if appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
-	itemResponses := []ItemResponse{}
-	for _, item := range orderDetail.Items {
-		itemResponse := ItemResponse{
-			ID:        item.ID,
-			OrderID:   item.OrderID,
-			ProductID: item.ProductID,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-		}
-		itemResponses = append(itemResponses, itemResponse)
-	}
-
 	status, res := response.Success(
 		"Success Get Order",
-		DetailResponse{
-			Order: Response{
-				ID:         orderDetail.Data.ID,
-				UserID:     orderDetail.Data.UserID,
-				TotalPrice: orderDetail.Data.TotalPrice,
-				Status:     orderDetail.Data.Status,
-			},
-			Items: itemResponses,
-		},
+		NewDetailResponse(orderDetail),
 	)
 	c.JSON(status, res)
 }
@@ -132,41 +97,31 @@ func (h *OrderHandler) Get(c *gin.Context) {
 func (h *OrderHandler) GetAll(c *gin.Context) {
 	userId := c.MustGet("user_id").(int)
 
-	orderDetails, appErr := h.orderService.GetByUserId(c.Request.Context(), userId)
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Query Parameters",
+			err,
+		))
+		return
+	}
+
+	orderDetails, pagination, appErr := h.orderService.GetByUserId(c.Request.Context(), userId, req.PageRequest())
 	if appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
-	var detailResponses []DetailResponse
+	detailResponses := []DetailResponse{}
 	for _, orderDetail := range orderDetails {
-		itemResponses := []ItemResponse{}
-		for _, item := range orderDetail.Items {
-			itemResponse := ItemResponse{
-				ID:        item.ID,
-				OrderID:   item.OrderID,
-				ProductID: item.ProductID,
-				Price:     item.Price,
-				Quantity:  item.Quantity,
-			}
-			itemResponses = append(itemResponses, itemResponse)
-		}
-
-		detailResponse := DetailResponse{
-			Order: Response{
-				ID:         orderDetail.Data.ID,
-				UserID:     orderDetail.Data.UserID,
-				TotalPrice: orderDetail.Data.TotalPrice,
-				Status:     orderDetail.Data.Status,
-			},
-			Items: itemResponses,
-		}
-		detailResponses = append(detailResponses, detailResponse)
+		detailResponses = append(detailResponses, NewDetailResponse(orderDetail))
 	}
 
-	status, res := response.Success(
+	status, res := response.SuccessPaginated(
 		"Success Get Orders",
 		detailResponses,
+		pagination,
 	)
 	c.JSON(status, res)
 }
@@ -203,7 +158,7 @@ func (h *OrderHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if appErr := h.orderService.UpdateStatus(c.Request.Context(), orderId, req.Status); appErr != nil {
+	if appErr := h.orderService.UpdateStatus(c.Request.Context(), middleware.Caller(c), orderId, req.Status); appErr != nil {
 		c.Error(appErr)
 		return
 	}
@@ -234,7 +189,7 @@ func (h *OrderHandler) Cancel(c *gin.Context) {
 		return
 	}
 
-	if appErr := h.orderService.Cancel(c.Request.Context(), orderId); appErr != nil {
+	if appErr := h.orderService.Cancel(c.Request.Context(), middleware.Caller(c), orderId); appErr != nil {
 		c.Error(appErr)
 		return
 	}
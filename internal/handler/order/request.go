@@ -1,16 +1,26 @@
 package order
 
-import "mini-ecommerce/internal/domain/order"
+import (
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/helper"
+)
 
 type CreateRequest struct {
-	Items []ItemRequest `json:"items" binding:"required"`
+	Items      []ItemRequest `json:"items" binding:"required,min=1,dive"`
+	CouponCode string        `json:"coupon_code" binding:"omitempty,max=50"`
+	AddressID  string        `json:"address_id"`
 }
 
 type ItemRequest struct {
-	ProductID string `json:"product_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required_without=VariantID"`
+	VariantID string `json:"variant_id"`
 	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
 type UpdateStatusRequest struct {
-	Status order.Status `json:"status" binding:"required"`
+	Status order.Status `json:"status" binding:"required,oneof=pending paid cancelled shipped delivered refunded"`
+}
+
+type ListRequest struct {
+	helper.PageQuery
 }
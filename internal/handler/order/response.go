@@ -1,23 +1,107 @@
 package order
 
-import "mini-ecommerce/internal/domain/order"
+import (
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/handler/shipment"
+	"mini-ecommerce/internal/response"
+)
 
 type Response struct {
-	ID         int          `json:"id"`
-	UserID     int          `json:"user_id"`
-	TotalPrice float64      `json:"total_price"`
-	Status     order.Status `json:"status"`
+	ID              int              `json:"id"`
+	UserID          int              `json:"user_id"`
+	Subtotal        response.Money   `json:"subtotal"`
+	Discount        response.Money   `json:"discount"`
+	Shipping        response.Money   `json:"shipping"`
+	Tax             response.Money   `json:"tax"`
+	TotalPrice      response.Money   `json:"total_price"`
+	Refunded        response.Money   `json:"refunded"`
+	CouponCode      string           `json:"coupon_code,omitempty"`
+	FreeShipping    bool             `json:"free_shipping"`
+	Region          string           `json:"region,omitempty"`
+	ShippingAddress *AddressResponse `json:"shipping_address,omitempty"`
+	Status          order.Status     `json:"status"`
+}
+
+type AddressResponse struct {
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Region     string `json:"region,omitempty"`
+	Country    string `json:"country"`
 }
 
 type ItemResponse struct {
-	ID        int     `json:"id"`
-	OrderID   int     `json:"oder_id"`
-	ProductID string  `json:"product_id"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
+	ID        int            `json:"id"`
+	OrderID   int            `json:"oder_id"`
+	ProductID string         `json:"product_id"`
+	VariantID string         `json:"variant_id"`
+	SKU       string         `json:"sku"`
+	Price     response.Money `json:"price"`
+	LineTotal response.Money `json:"line_total"`
+	Quantity  int            `json:"quantity"`
 }
 
 type DetailResponse struct {
-	Order Response       `json:"order"`
-	Items []ItemResponse `json:"items"`
+	Order     Response            `json:"order"`
+	Items     []ItemResponse      `json:"items"`
+	Shipments []shipment.Response `json:"shipments"`
+}
+
+func NewDetailResponse(orderDetail order.Detail) DetailResponse {
+	itemResponses := []ItemResponse{}
+	for _, item := range orderDetail.Items {
+		itemResponse := ItemResponse{
+			ID:        item.ID,
+			OrderID:   item.OrderID,
+			ProductID: item.ProductID,
+			VariantID: item.VariantID,
+			SKU:       item.SKU,
+			Price:     response.NewMoney(item.Price),
+			LineTotal: response.NewMoney(item.LineTotal()),
+			Quantity:  item.Quantity,
+		}
+		itemResponses = append(itemResponses, itemResponse)
+	}
+
+	shipmentResponses := []shipment.Response{}
+	for _, shipmentData := range orderDetail.Shipments {
+		shipmentResponses = append(shipmentResponses, shipment.NewResponse(shipmentData))
+	}
+
+	var addressResponse *AddressResponse
+	if shippingAddress := orderDetail.Data.ShippingAddress; shippingAddress != nil {
+		addressResponse = &AddressResponse{
+			Recipient:  shippingAddress.Recipient,
+			Phone:      shippingAddress.Phone,
+			Line1:      shippingAddress.Line1,
+			Line2:      shippingAddress.Line2,
+			City:       shippingAddress.City,
+			PostalCode: shippingAddress.PostalCode,
+			Region:     shippingAddress.Region,
+			Country:    shippingAddress.Country,
+		}
+	}
+
+	return DetailResponse{
+		Order: Response{
+			ID:              orderDetail.Data.ID,
+			UserID:          orderDetail.Data.UserID,
+			Subtotal:        response.NewMoney(orderDetail.Data.Subtotal),
+			Discount:        response.NewMoney(orderDetail.Data.Discount),
+			Shipping:        response.NewMoney(orderDetail.Data.Shipping),
+			Tax:             response.NewMoney(orderDetail.Data.Tax),
+			TotalPrice:      response.NewMoney(orderDetail.Data.TotalPrice),
+			Refunded:        response.NewMoney(orderDetail.Data.Refunded),
+			CouponCode:      orderDetail.Data.CouponCode,
+			FreeShipping:    orderDetail.Data.FreeShipping,
+			Region:          orderDetail.Data.Region,
+			ShippingAddress: addressResponse,
+			Status:          orderDetail.Data.Status,
+		},
+		Items:     itemResponses,
+		Shipments: shipmentResponses,
+	}
 }
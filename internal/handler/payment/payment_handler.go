@@ -0,0 +1,141 @@
+package payment
+
+import (
+	"errors"
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/middleware"
+	"mini-ecommerce/internal/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService payment.Service
+}
+
+func NewHandler(paymentService payment.Service) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+func (h *PaymentHandler) Create(c *gin.Context) {
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	paymentData, appErr := h.paymentService.Create(c.Request.Context(), middleware.Caller(c), req.OrderID, req.PaymentMethod)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	// The gateway could not say how the charge ended; it is settled later
+	// and can be followed through GET /payments/:id.
+	if paymentData.Status == payment.StatusPending {
+		status, res := response.Accepted(
+			"Payment Is Being Processed",
+			toResponse(paymentData),
+		)
+		c.JSON(status, res)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Create Payment",
+		toResponse(paymentData),
+	)
+	c.JSON(status, res)
+}
+
+func (h *PaymentHandler) Get(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Payment id is required"),
+		))
+		return
+	}
+
+	paymentId, err := strconv.Atoi(id)
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Payment id must be a number"),
+		))
+		return
+	}
+
+	paymentData, appErr := h.paymentService.Get(c.Request.Context(), middleware.Caller(c), paymentId)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Get Payment",
+		toResponse(paymentData),
+	)
+	c.JSON(status, res)
+}
+
+func (h *PaymentHandler) GetByOrder(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Order id is required"),
+		))
+		return
+	}
+
+	orderId, err := strconv.Atoi(id)
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Order id must be a number"),
+		))
+		return
+	}
+
+	payments, appErr := h.paymentService.GetByOrderId(c.Request.Context(), middleware.Caller(c), orderId)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	paymentResponses := []Response{}
+	for _, paymentData := range payments {
+		paymentResponses = append(paymentResponses, toResponse(paymentData))
+	}
+
+	status, res := response.Success(
+		"Success Get Payments",
+		paymentResponses,
+	)
+	c.JSON(status, res)
+}
+
+func toResponse(paymentData payment.Data) Response {
+	return Response{
+		ID:            paymentData.ID,
+		OrderID:       paymentData.OrderID,
+		PaymentMethod: paymentData.Method,
+		Status:        paymentData.Status,
+		Amount:        response.NewMoney(paymentData.Amount),
+		Reference:     paymentData.Reference,
+		PaidAt:        paymentData.PaidAt,
+	}
+}
@@ -0,0 +1,8 @@
+package payment
+
+import "mini-ecommerce/internal/domain/payment"
+
+type CreateRequest struct {
+	OrderID       int            `json:"order_id" binding:"required,gt=0"`
+	PaymentMethod payment.Method `json:"payment_method" binding:"required,oneof=transfer ewallet"`
+}
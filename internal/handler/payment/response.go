@@ -0,0 +1,17 @@
+package payment
+
+import (
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/response"
+	"time"
+)
+
+type Response struct {
+	ID            int            `json:"id"`
+	OrderID       int            `json:"order_id"`
+	PaymentMethod payment.Method `json:"payment_method"`
+	Status        payment.Status `json:"status"`
+	Amount        response.Money `json:"amount"`
+	Reference     string         `json:"reference"`
+	PaidAt        *time.Time     `json:"paid_at"`
+}
@@ -0,0 +1,133 @@
+package pricing
+
+import (
+	"mini-ecommerce/internal/domain/pricing"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"mini-ecommerce/internal/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingHandler struct {
+	pricingService pricing.Service
+}
+
+func NewHandler(pricingService pricing.Service) *PricingHandler {
+	return &PricingHandler{pricingService: pricingService}
+}
+
+func (h *PricingHandler) CreateShippingRate(c *gin.Context) {
+	var req CreateShippingRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	rate := pricing.ShippingRate{
+		Region:         req.Region,
+		MinWeightGrams: req.MinWeightGrams,
+		Fee:            money.New(req.Fee, req.Currency),
+	}
+	if appErr := h.pricingService.CreateShippingRate(c.Request.Context(), &rate); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Create Shipping Rate",
+		newShippingRateResponse(rate),
+	)
+	c.JSON(status, res)
+}
+
+func (h *PricingHandler) GetShippingRates(c *gin.Context) {
+	rates, appErr := h.pricingService.GetShippingRates(c.Request.Context())
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	rateResponses := []ShippingRateResponse{}
+	for _, rate := range rates {
+		rateResponses = append(rateResponses, newShippingRateResponse(rate))
+	}
+
+	status, res := response.Success(
+		"Success Get Shipping Rates",
+		rateResponses,
+	)
+	c.JSON(status, res)
+}
+
+func (h *PricingHandler) DeleteShippingRate(c *gin.Context) {
+	if appErr := h.pricingService.DeleteShippingRate(c.Request.Context(), c.Param("id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Delete Shipping Rate")
+	c.JSON(status, res)
+}
+
+func (h *PricingHandler) CreateTaxRate(c *gin.Context) {
+	var req CreateTaxRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	rate := pricing.TaxRate{
+		Region:      req.Region,
+		CategoryID:  req.CategoryID,
+		BasisPoints: req.BasisPoints,
+	}
+	if appErr := h.pricingService.CreateTaxRate(c.Request.Context(), &rate); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Create Tax Rate",
+		newTaxRateResponse(rate),
+	)
+	c.JSON(status, res)
+}
+
+func (h *PricingHandler) GetTaxRates(c *gin.Context) {
+	rates, appErr := h.pricingService.GetTaxRates(c.Request.Context())
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	rateResponses := []TaxRateResponse{}
+	for _, rate := range rates {
+		rateResponses = append(rateResponses, newTaxRateResponse(rate))
+	}
+
+	status, res := response.Success(
+		"Success Get Tax Rates",
+		rateResponses,
+	)
+	c.JSON(status, res)
+}
+
+func (h *PricingHandler) DeleteTaxRate(c *gin.Context) {
+	if appErr := h.pricingService.DeleteTaxRate(c.Request.Context(), c.Param("id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Delete Tax Rate")
+	c.JSON(status, res)
+}
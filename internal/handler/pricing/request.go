@@ -0,0 +1,18 @@
+package pricing
+
+// CreateShippingRateRequest takes the fee in minor units of currency, which
+// defaults to the store currency. Leave region empty for every region.
+type CreateShippingRateRequest struct {
+	Region         string `json:"region" binding:"omitempty,max=20"`
+	MinWeightGrams int    `json:"min_weight_grams" binding:"gte=0"`
+	Fee            int64  `json:"fee" binding:"gte=0"`
+	Currency       string `json:"currency" binding:"omitempty,len=3"`
+}
+
+// CreateTaxRateRequest takes basis_points as the rate, so 1100 is 11%. Leave
+// region or category_id empty to match any.
+type CreateTaxRateRequest struct {
+	Region      string `json:"region" binding:"omitempty,max=20"`
+	CategoryID  string `json:"category_id"`
+	BasisPoints int64  `json:"basis_points" binding:"gte=0,lte=10000"`
+}
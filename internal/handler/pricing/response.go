@@ -0,0 +1,38 @@
+package pricing
+
+import (
+	"mini-ecommerce/internal/domain/pricing"
+	"mini-ecommerce/internal/response"
+)
+
+type ShippingRateResponse struct {
+	ID             string         `json:"id"`
+	Region         string         `json:"region,omitempty"`
+	MinWeightGrams int            `json:"min_weight_grams"`
+	Fee            response.Money `json:"fee"`
+}
+
+type TaxRateResponse struct {
+	ID          string `json:"id"`
+	Region      string `json:"region,omitempty"`
+	CategoryID  string `json:"category_id,omitempty"`
+	BasisPoints int64  `json:"basis_points"`
+}
+
+func newShippingRateResponse(rate pricing.ShippingRate) ShippingRateResponse {
+	return ShippingRateResponse{
+		ID:             rate.ID,
+		Region:         rate.Region,
+		MinWeightGrams: rate.MinWeightGrams,
+		Fee:            response.NewMoney(rate.Fee),
+	}
+}
+
+func newTaxRateResponse(rate pricing.TaxRate) TaxRateResponse {
+	return TaxRateResponse{
+		ID:          rate.ID,
+		Region:      rate.Region,
+		CategoryID:  rate.CategoryID,
+		BasisPoints: rate.BasisPoints,
+	}
+}
@@ -2,8 +2,10 @@ package product
 
 import (
 	"errors"
+	"io"
 	"mini-ecommerce/internal/domain/product"
 	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
 	"mini-ecommerce/internal/response"
 	"net/http"
 
@@ -11,11 +13,18 @@ import (
 )
 
 type ProductHandler struct {
-	productService product.Service
+	productService      product.Service
+	productImageService product.ImageService
+	maxImageBytes       int64
 }
 
-func NewHandler(productService product.Service) *ProductHandler {
-	return &ProductHandler{productService: productService}
+// NewHandler accepts image uploads of at most maxImageBytes.
+func NewHandler(productService product.Service, productImageService product.ImageService, maxImageBytes int64) *ProductHandler {
+	return &ProductHandler{
+		productService:      productService,
+		productImageService: productImageService,
+		maxImageBytes:       maxImageBytes,
+	}
 }
 
 func (h *ProductHandler) Create(c *gin.Context) {
@@ -29,28 +38,40 @@ func (h *ProductHandler) Create(c *gin.Context) {
 		return
 	}
 
-	productData := product.Data{
-		CategoryID:  req.CategoryID,
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Stock:       req.Stock,
+	detail := product.Detail{
+		Data: product.Data{
+			CategoryID:  req.CategoryID,
+			Name:        req.Name,
+			Description: req.Description,
+			Price:       money.New(req.Price, req.Currency),
+			WeightGrams: req.WeightGrams,
+			Stock:       req.Stock,
+		},
+	}
+	if len(req.Variants) == 0 {
+		detail.Variants = []product.Variant{{SKU: req.SKU, Stock: req.Stock}}
+	}
+	for _, variantReq := range req.Variants {
+		variant := product.Variant{
+			SKU:     variantReq.SKU,
+			Options: variantReq.Options,
+			Stock:   variantReq.Stock,
+		}
+		if variantReq.Price != nil {
+			variant.Price.Amount = *variantReq.Price
+			variant.PriceOverride = true
+		}
+		detail.Variants = append(detail.Variants, variant)
 	}
-	if appErr := h.productService.Create(c.Request.Context(), &productData); appErr != nil {
+
+	if appErr := h.productService.Create(c.Request.Context(), &detail); appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
 	status, res := response.Success(
 		"Success Create Product",
-		Response{
-			ID:          productData.ID,
-			CategoryID:  productData.CategoryID,
-			Name:        productData.Name,
-			Description: productData.Description,
-			Price:       productData.Price,
-			Stock:       productData.Stock,
-		},
+		newDetailResponse(detail),
 	)
 	c.JSON(status, res)
 }
@@ -66,7 +87,7 @@ func (h *ProductHandler) Get(c *gin.Context) {
 		return
 	}
 
-	productData, appErr := h.productService.Get(c.Request.Context(), id)
+	detail, appErr := h.productService.Get(c.Request.Context(), id)
 	if appErr != nil {
 		c.Error(appErr)
 		return
@@ -74,41 +95,83 @@ func (h *ProductHandler) Get(c *gin.Context) {
 
 	status, res := response.Success(
 		"Success Get Product",
-		Response{
-			ID:          productData.ID,
-			CategoryID:  productData.CategoryID,
-			Name:        productData.Name,
-			Description: productData.Description,
-			Price:       productData.Price,
-			Stock:       productData.Stock,
-		},
+		newDetailResponse(detail),
 	)
 	c.JSON(status, res)
 }
 
 func (h *ProductHandler) GetAll(c *gin.Context) {
-	products, appErr := h.productService.GetAll(c.Request.Context())
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Query Parameters",
+			err,
+		))
+		return
+	}
+
+	filter := product.Filter{
+		CategoryID:         req.CategoryID,
+		IncludeDescendants: req.IncludeDescendants,
+		MinPrice:           req.MinPrice,
+		MaxPrice:           req.MaxPrice,
+		InStock:            req.InStock,
+		Search:             req.Search,
+		SortBy:             product.SortField(req.Sort),
+		Descending:         req.Order == "desc",
+		Page:               req.PageRequest(),
+	}
+	products, pagination, appErr := h.productService.GetAll(c.Request.Context(), filter)
 	if appErr != nil {
 		c.Error(appErr)
 		return
 	}
 
-	var dataResponses []Response
+	dataResponses := []Response{}
 	for _, product := range products {
-		response := Response{
-			ID:          product.ID,
-			CategoryID:  product.CategoryID,
-			Name:        product.Name,
-			Description: product.Description,
-			Price:       product.Price,
-			Stock:       product.Stock,
-		}
-		dataResponses = append(dataResponses, response)
+		dataResponses = append(dataResponses, newResponse(product))
 	}
 
-	status, res := response.Success(
+	status, res := response.SuccessPaginated(
 		"Success Get Products",
 		dataResponses,
+		pagination,
+	)
+	c.JSON(status, res)
+}
+
+func (h *ProductHandler) Search(c *gin.Context) {
+	var req SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Query Parameters",
+			err,
+		))
+		return
+	}
+
+	results, pagination, appErr := h.productService.Search(c.Request.Context(), req.Query, req.PageRequest())
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	searchResponses := []SearchResponse{}
+	for _, result := range results {
+		searchResponses = append(searchResponses, SearchResponse{
+			Response:      newResponse(result.Data),
+			Rank:          result.Rank,
+			NameHighlight: result.NameHighlight,
+			Snippet:       result.Snippet,
+		})
+	}
+
+	status, res := response.SuccessPaginated(
+		"Success Search Products",
+		searchResponses,
+		pagination,
 	)
 	c.JSON(status, res)
 }
@@ -129,9 +192,17 @@ func (h *ProductHandler) Update(c *gin.Context) {
 		CategoryID:  req.CategoryID,
 		Name:        req.Name,
 		Description: req.Description,
-		Price:       req.Price,
+		WeightGrams: req.WeightGrams,
 		Stock:       req.Stock,
 	}
+	if req.Price != nil {
+		currency := ""
+		if req.Currency != nil {
+			currency = *req.Currency
+		}
+		price := money.New(*req.Price, currency)
+		productUpdate.Price = &price
+	}
 	if appErr := h.productService.Update(c.Request.Context(), &productUpdate); appErr != nil {
 		c.Error(appErr)
 		return
@@ -144,7 +215,8 @@ func (h *ProductHandler) Update(c *gin.Context) {
 			CategoryID:  *productUpdate.CategoryID,
 			Name:        *productUpdate.Name,
 			Description: *productUpdate.Description,
-			Price:       *productUpdate.Price,
+			Price:       response.NewMoney(*productUpdate.Price),
+			WeightGrams: *productUpdate.WeightGrams,
 			Stock:       *productUpdate.Stock,
 		},
 	)
@@ -170,3 +242,180 @@ func (h *ProductHandler) Delete(c *gin.Context) {
 	status, res := response.SuccessNoContent("Success Deleted Product")
 	c.JSON(status, res)
 }
+
+func (h *ProductHandler) AddVariant(c *gin.Context) {
+	var req AddVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	variant := product.Variant{
+		ProductID: c.Param("id"),
+		SKU:       req.SKU,
+		Options:   req.Options,
+		Stock:     req.Stock,
+	}
+	if req.Price != nil {
+		variant.Price.Amount = *req.Price
+		variant.PriceOverride = true
+	}
+
+	if appErr := h.productService.AddVariant(c.Request.Context(), &variant); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Add Product Variant",
+		newVariantResponse(variant),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ProductHandler) UpdateVariant(c *gin.Context) {
+	var req UpdateVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	variant, appErr := h.productService.UpdateVariant(c.Request.Context(), product.VariantUpdate{
+		ID:        c.Param("variant_id"),
+		ProductID: c.Param("id"),
+		SKU:       req.SKU,
+		Options:   req.Options,
+		Price:     req.Price,
+		Stock:     req.Stock,
+	})
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Update Product Variant",
+		newVariantResponse(variant),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ProductHandler) DeleteVariant(c *gin.Context) {
+	if appErr := h.productService.DeleteVariant(c.Request.Context(), c.Param("id"), c.Param("variant_id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Delete Product Variant")
+	c.JSON(status, res)
+}
+
+// multipartOverhead leaves room for the multipart boundaries and headers
+// around the image itself.
+const multipartOverhead = 64 << 10
+
+// UploadImage takes a multipart form with the file in the "image" field.
+func (h *ProductHandler) UploadImage(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxImageBytes+multipartOverhead)
+
+	file, _, err := c.Request.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.Error(helper.NewAppError(
+				http.StatusRequestEntityTooLarge,
+				"Image Too Large",
+				helper.ErrImageTooLarge,
+			))
+			return
+		}
+
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+	defer file.Close()
+
+	// One byte past the limit is enough for the service to reject it.
+	data, err := io.ReadAll(io.LimitReader(file, h.maxImageBytes+1))
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	image, appErr := h.productImageService.Upload(c.Request.Context(), c.Param("id"), data)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Upload Product Image",
+		newImageResponse(image),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ProductHandler) SetPrimaryImage(c *gin.Context) {
+	if appErr := h.productImageService.SetPrimary(c.Request.Context(), c.Param("id"), c.Param("image_id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Set Primary Product Image")
+	c.JSON(status, res)
+}
+
+func (h *ProductHandler) ReorderImages(c *gin.Context) {
+	var req ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	images, appErr := h.productImageService.Reorder(c.Request.Context(), c.Param("id"), req.ImageIDs)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	imageResponses := []ImageResponse{}
+	for _, image := range images {
+		imageResponses = append(imageResponses, newImageResponse(image))
+	}
+
+	status, res := response.Success(
+		"Success Reorder Product Images",
+		imageResponses,
+	)
+	c.JSON(status, res)
+}
+
+func (h *ProductHandler) DeleteImage(c *gin.Context) {
+	if appErr := h.productImageService.Delete(c.Request.Context(), c.Param("id"), c.Param("image_id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Delete Product Image")
+	c.JSON(status, res)
+}
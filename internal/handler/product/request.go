@@ -1,18 +1,72 @@
 package product
 
+import "mini-ecommerce/internal/helper"
+
 type CreateRequest struct {
-	CategoryID  string  `json:"category_id" binding:"required,gt=0"`
-	Name        string  `json:"name" binding:"required,min=3,max=50"`
-	Description string  `json:"description" binding:"omitempty,max=255"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Stock       int     `json:"stock" binding:"required,gte=0"`
+	CategoryID  string `json:"category_id" binding:"required,gt=0"`
+	Name        string `json:"name" binding:"required,min=3,max=50"`
+	Description string `json:"description" binding:"omitempty,max=255"`
+	Price       int64  `json:"price" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"omitempty,len=3"`
+	WeightGrams int    `json:"weight_grams" binding:"gte=0"`
+	// Stock and SKU describe the single variant of a product created without
+	// Variants.
+	Stock    int              `json:"stock" binding:"required_without=Variants,gte=0"`
+	SKU      string           `json:"sku" binding:"omitempty,max=64"`
+	Variants []VariantRequest `json:"variants" binding:"omitempty,dive"`
+}
+
+type VariantRequest struct {
+	SKU     string            `json:"sku" binding:"omitempty,max=64"`
+	Options map[string]string `json:"options" binding:"omitempty,dive,keys,min=1,max=30,endkeys,min=1,max=50"`
+	Price   *int64            `json:"price" binding:"omitempty,gt=0"`
+	Stock   int               `json:"stock" binding:"gte=0"`
+}
+
+type AddVariantRequest struct {
+	SKU     string            `json:"sku" binding:"required,max=64"`
+	Options map[string]string `json:"options" binding:"omitempty,dive,keys,min=1,max=30,endkeys,min=1,max=50"`
+	Price   *int64            `json:"price" binding:"omitempty,gt=0"`
+	Stock   int               `json:"stock" binding:"gte=0"`
+}
+
+type UpdateVariantRequest struct {
+	SKU     *string           `json:"sku" binding:"omitempty,min=1,max=64"`
+	Options map[string]string `json:"options" binding:"omitempty,dive,keys,min=1,max=30,endkeys,min=1,max=50"`
+	Price   *int64            `json:"price" binding:"omitempty,gt=0"`
+	Stock   *int              `json:"stock" binding:"omitempty,gte=0"`
+}
+
+// ReorderImagesRequest lists every image id of the product in display order.
+type ReorderImagesRequest struct {
+	ImageIDs []string `json:"image_ids" binding:"required,min=1,dive,required"`
 }
 
 type UpdateRequest struct {
-	ID          string   `json:"id" binding:"required"`
-	CategoryID  *string  `json:"category_id,omitempty"`
-	Name        *string  `json:"name" binding:"omitempty,min=3,max=50"`
-	Description *string  `json:"description" binding:"omitempty,max=255"`
-	Price       *float64 `json:"price,omitempty"`
-	Stock       *int     `json:"stock,omitempty"`
+	ID          string  `json:"id" binding:"required"`
+	CategoryID  *string `json:"category_id,omitempty"`
+	Name        *string `json:"name" binding:"omitempty,min=3,max=50"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	Price       *int64  `json:"price,omitempty" binding:"omitempty,gt=0"`
+	Currency    *string `json:"currency,omitempty" binding:"omitempty,len=3"`
+	WeightGrams *int    `json:"weight_grams,omitempty" binding:"omitempty,gte=0"`
+	Stock       *int    `json:"stock,omitempty"`
+}
+
+type ListRequest struct {
+	helper.PageQuery
+	CategoryID string `form:"category_id"`
+	// IncludeDescendants also lists products of every subcategory.
+	IncludeDescendants bool   `form:"include_descendants"`
+	MinPrice           *int64 `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice           *int64 `form:"max_price" binding:"omitempty,gte=0"`
+	InStock            bool   `form:"in_stock"`
+	Search             string `form:"search" binding:"omitempty,max=100"`
+	Sort               string `form:"sort" binding:"omitempty,oneof=created_at price name"`
+	Order              string `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+type SearchRequest struct {
+	helper.PageQuery
+	Query string `form:"q" binding:"required,max=100"`
 }
@@ -1,10 +1,129 @@
 package product
 
+import (
+	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/response"
+)
+
 type Response struct {
-	ID          string  `json:"id"`
-	CategoryID  string  `json:"category_id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	Stock       int     `json:"stock"`
+	ID          string         `json:"id"`
+	CategoryID  string         `json:"category_id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Price       response.Money `json:"price"`
+	WeightGrams int            `json:"weight_grams"`
+	Stock       int            `json:"stock"`
+	// ImageURL and ThumbnailURL are those of the primary image, if any.
+	ImageURL     string `json:"image_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+type SearchResponse struct {
+	Response
+	Rank          float64 `json:"rank"`
+	NameHighlight string  `json:"name_highlight"`
+	Snippet       string  `json:"snippet"`
+}
+
+type VariantResponse struct {
+	ID            string            `json:"id"`
+	SKU           string            `json:"sku"`
+	Options       map[string]string `json:"options"`
+	Price         response.Money    `json:"price"`
+	PriceOverride bool              `json:"price_override"`
+	Stock         int               `json:"stock"`
+}
+
+type ImageResponse struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Position     int    `json:"position"`
+	Primary      bool   `json:"primary"`
+}
+
+// DetailResponse is a product with its variant matrix: Options lists the
+// values of every option axis, e.g. {"size": ["S", "M"]}, and Variants the
+// sellable combinations. Images are in display order.
+type DetailResponse struct {
+	Response
+	Options  map[string][]string `json:"options"`
+	Variants []VariantResponse   `json:"variants"`
+	Images   []ImageResponse     `json:"images"`
+}
+
+func newResponse(productData product.Data) Response {
+	productResponse := Response{
+		ID:          productData.ID,
+		CategoryID:  productData.CategoryID,
+		Name:        productData.Name,
+		Description: productData.Description,
+		Price:       response.NewMoney(productData.Price),
+		WeightGrams: productData.WeightGrams,
+		Stock:       productData.Stock,
+	}
+
+	if productData.PrimaryImage != nil {
+		productResponse.ImageURL = productData.PrimaryImage.URL
+		productResponse.ThumbnailURL = productData.PrimaryImage.ThumbnailURL
+	}
+
+	return productResponse
+}
+
+func newImageResponse(image product.Image) ImageResponse {
+	return ImageResponse{
+		ID:           image.ID,
+		URL:          image.URL,
+		ThumbnailURL: image.ThumbnailURL,
+		ContentType:  image.ContentType,
+		Width:        image.Width,
+		Height:       image.Height,
+		Position:     image.Position,
+		Primary:      image.Primary,
+	}
+}
+
+func newVariantResponse(variant product.Variant) VariantResponse {
+	return VariantResponse{
+		ID:            variant.ID,
+		SKU:           variant.SKU,
+		Options:       variant.Options,
+		Price:         response.NewMoney(variant.Price),
+		PriceOverride: variant.PriceOverride,
+		Stock:         variant.Stock,
+	}
+}
+
+func newDetailResponse(detail product.Detail) DetailResponse {
+	detailResponse := DetailResponse{
+		Response: newResponse(detail.Data),
+		Options:  map[string][]string{},
+		Variants: []VariantResponse{},
+		Images:   []ImageResponse{},
+	}
+
+	for _, image := range detail.Images {
+		detailResponse.Images = append(detailResponse.Images, newImageResponse(image))
+	}
+
+	seen := map[string]map[string]bool{}
+	for _, variant := range detail.Variants {
+		detailResponse.Variants = append(detailResponse.Variants, newVariantResponse(variant))
+
+		for name, value := range variant.Options {
+			if seen[name] == nil {
+				seen[name] = map[string]bool{}
+			}
+			if !seen[name][value] {
+				seen[name][value] = true
+				detailResponse.Options[name] = append(detailResponse.Options[name], value)
+			}
+		}
+	}
+
+	return detailResponse
 }
@@ -0,0 +1,110 @@
+package promotion
+
+import (
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"mini-ecommerce/internal/response"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionService promotion.Service
+}
+
+func NewHandler(promotionService promotion.Service) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+func (h *PromotionHandler) Create(c *gin.Context) {
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	coupon := promotion.Coupon{
+		Code:         req.Code,
+		Kind:         req.Kind,
+		PercentOff:   req.PercentOff,
+		AmountOff:    money.New(req.AmountOff, req.Currency),
+		MinOrder:     money.New(req.MinOrder, req.Currency),
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		ProductIDs:   req.ProductIDs,
+		CategoryIDs:  req.CategoryIDs,
+		Active:       true,
+	}
+	if appErr := h.promotionService.Create(c.Request.Context(), &coupon); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Create Coupon",
+		newResponse(coupon),
+	)
+	c.JSON(status, res)
+}
+
+func (h *PromotionHandler) Get(c *gin.Context) {
+	coupon, appErr := h.promotionService.Get(c.Request.Context(), c.Param("id"))
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Get Coupon",
+		newResponse(coupon),
+	)
+	c.JSON(status, res)
+}
+
+func (h *PromotionHandler) GetAll(c *gin.Context) {
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Query Parameters",
+			err,
+		))
+		return
+	}
+
+	coupons, pagination, appErr := h.promotionService.GetAll(c.Request.Context(), req.PageRequest())
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	couponResponses := []Response{}
+	for _, coupon := range coupons {
+		couponResponses = append(couponResponses, newResponse(coupon))
+	}
+
+	status, res := response.SuccessPaginated(
+		"Success Get Coupons",
+		couponResponses,
+		pagination,
+	)
+	c.JSON(status, res)
+}
+
+func (h *PromotionHandler) Deactivate(c *gin.Context) {
+	if appErr := h.promotionService.Deactivate(c.Request.Context(), c.Param("id")); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Deactivate Coupon")
+	c.JSON(status, res)
+}
@@ -0,0 +1,28 @@
+package promotion
+
+import (
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/helper"
+	"time"
+)
+
+// CreateRequest takes percent_off in basis points (1500 is 15%) and amounts in
+// minor units of currency, which defaults to the store currency.
+type CreateRequest struct {
+	Code         string         `json:"code" binding:"required,min=3,max=50"`
+	Kind         promotion.Kind `json:"kind" binding:"required,oneof=percentage fixed_amount free_shipping"`
+	PercentOff   int64          `json:"percent_off" binding:"omitempty,gt=0,lte=10000"`
+	AmountOff    int64          `json:"amount_off" binding:"omitempty,gt=0"`
+	MinOrder     int64          `json:"min_order" binding:"gte=0"`
+	Currency     string         `json:"currency" binding:"omitempty,len=3"`
+	StartsAt     *time.Time     `json:"starts_at"`
+	EndsAt       *time.Time     `json:"ends_at"`
+	UsageLimit   int            `json:"usage_limit" binding:"gte=0"`
+	PerUserLimit int            `json:"per_user_limit" binding:"gte=0"`
+	ProductIDs   []string       `json:"product_ids" binding:"omitempty,dive,required"`
+	CategoryIDs  []string       `json:"category_ids" binding:"omitempty,dive,required"`
+}
+
+type ListRequest struct {
+	helper.PageQuery
+}
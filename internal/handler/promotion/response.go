@@ -0,0 +1,51 @@
+package promotion
+
+import (
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/response"
+	"time"
+)
+
+type Response struct {
+	ID           string         `json:"id"`
+	Code         string         `json:"code"`
+	Kind         promotion.Kind `json:"kind"`
+	PercentOff   int64          `json:"percent_off"`
+	AmountOff    response.Money `json:"amount_off"`
+	MinOrder     response.Money `json:"min_order"`
+	StartsAt     *time.Time     `json:"starts_at"`
+	EndsAt       *time.Time     `json:"ends_at"`
+	UsageLimit   int            `json:"usage_limit"`
+	PerUserLimit int            `json:"per_user_limit"`
+	ProductIDs   []string       `json:"product_ids"`
+	CategoryIDs  []string       `json:"category_ids"`
+	Active       bool           `json:"active"`
+}
+
+func newResponse(coupon promotion.Coupon) Response {
+	productIds := coupon.ProductIDs
+	if productIds == nil {
+		productIds = []string{}
+	}
+
+	categoryIds := coupon.CategoryIDs
+	if categoryIds == nil {
+		categoryIds = []string{}
+	}
+
+	return Response{
+		ID:           coupon.ID,
+		Code:         coupon.Code,
+		Kind:         coupon.Kind,
+		PercentOff:   coupon.PercentOff,
+		AmountOff:    response.NewMoney(coupon.AmountOff),
+		MinOrder:     response.NewMoney(coupon.MinOrder),
+		StartsAt:     coupon.StartsAt,
+		EndsAt:       coupon.EndsAt,
+		UsageLimit:   coupon.UsageLimit,
+		PerUserLimit: coupon.PerUserLimit,
+		ProductIDs:   productIds,
+		CategoryIDs:  categoryIds,
+		Active:       coupon.Active,
+	}
+}
@@ -0,0 +1,33 @@
+package rma
+
+import "mini-ecommerce/internal/helper"
+
+type CreateRequest struct {
+	Reason string        `json:"reason" binding:"required,max=500"`
+	Items  []ItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type ItemRequest struct {
+	OrderItemID int `json:"order_item_id" binding:"required"`
+	Quantity    int `json:"quantity" binding:"required,min=1"`
+}
+
+// ResolveRequest approves or rejects a return with an optional note for the
+// customer.
+type ResolveRequest struct {
+	Note string `json:"note" binding:"max=500"`
+}
+
+type ReceiveRequest struct {
+	Restock bool `json:"restock"`
+}
+
+// RefundRequest refunds amount, in minor units of the order currency; leave
+// it out to refund the full value of the returned items.
+type RefundRequest struct {
+	Amount *int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+type ListRequest struct {
+	helper.PageQuery
+}
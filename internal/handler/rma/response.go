@@ -0,0 +1,69 @@
+package rma
+
+import (
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/domain/rma"
+	"mini-ecommerce/internal/response"
+)
+
+type Response struct {
+	ID             string         `json:"id"`
+	OrderID        int            `json:"order_id"`
+	UserID         int            `json:"user_id"`
+	Status         rma.Status     `json:"status"`
+	Reason         string         `json:"reason"`
+	ResolutionNote string         `json:"resolution_note,omitempty"`
+	Restocked      bool           `json:"restocked"`
+	Refunded       response.Money `json:"refunded"`
+	Items          []ItemResponse `json:"items"`
+}
+
+type ItemResponse struct {
+	OrderItemID int `json:"order_item_id"`
+	Quantity    int `json:"quantity"`
+}
+
+type RefundResponse struct {
+	ID        string         `json:"id"`
+	PaymentID int            `json:"payment_id"`
+	Amount    response.Money `json:"amount"`
+	Status    payment.Status `json:"status"`
+	Reference string         `json:"reference,omitempty"`
+}
+
+type RefundResultResponse struct {
+	Return Response       `json:"return"`
+	Refund RefundResponse `json:"refund"`
+}
+
+func NewResponse(data rma.Return) Response {
+	itemResponses := []ItemResponse{}
+	for _, item := range data.Items {
+		itemResponses = append(itemResponses, ItemResponse{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	return Response{
+		ID:             data.ID,
+		OrderID:        data.OrderID,
+		UserID:         data.UserID,
+		Status:         data.Status,
+		Reason:         data.Reason,
+		ResolutionNote: data.ResolutionNote,
+		Restocked:      data.Restocked,
+		Refunded:       response.NewMoney(data.Refunded),
+		Items:          itemResponses,
+	}
+}
+
+func NewRefundResponse(refund rma.Refund) RefundResponse {
+	return RefundResponse{
+		ID:        refund.ID,
+		PaymentID: refund.PaymentID,
+		Amount:    response.NewMoney(refund.Amount),
+		Status:    refund.Status,
+		Reference: refund.Reference,
+	}
+}
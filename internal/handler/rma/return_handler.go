@@ -0,0 +1,246 @@
+package rma
+
+import (
+	"errors"
+	"io"
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/domain/rma"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/middleware"
+	"mini-ecommerce/internal/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnHandler struct {
+	returnService rma.Service
+}
+
+func NewHandler(returnService rma.Service) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+func (h *ReturnHandler) Create(c *gin.Context) {
+	orderId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Order id must be a number"),
+		))
+		return
+	}
+
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	data := rma.Return{
+		OrderID: orderId,
+		Reason:  req.Reason,
+	}
+	for _, item := range req.Items {
+		data.Items = append(data.Items, rma.Item{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	if appErr := h.returnService.Request(c.Request.Context(), middleware.Caller(c), &data); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Request Return",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) Get(c *gin.Context) {
+	data, appErr := h.returnService.Get(c.Request.Context(), middleware.Caller(c), c.Param("id"))
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Get Return",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) GetByOrder(c *gin.Context) {
+	orderId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Order id must be a number"),
+		))
+		return
+	}
+
+	returns, appErr := h.returnService.GetByOrderId(c.Request.Context(), middleware.Caller(c), orderId)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	returnResponses := []Response{}
+	for _, data := range returns {
+		returnResponses = append(returnResponses, NewResponse(data))
+	}
+
+	status, res := response.Success(
+		"Success Get Returns",
+		returnResponses,
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) GetAll(c *gin.Context) {
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Query Parameters",
+			err,
+		))
+		return
+	}
+
+	returns, pagination, appErr := h.returnService.GetAll(c.Request.Context(), req.PageRequest())
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	returnResponses := []Response{}
+	for _, data := range returns {
+		returnResponses = append(returnResponses, NewResponse(data))
+	}
+
+	status, res := response.SuccessPaginated(
+		"Success Get Returns",
+		returnResponses,
+		pagination,
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) Approve(c *gin.Context) {
+	var req ResolveRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	data, appErr := h.returnService.Approve(c.Request.Context(), middleware.Caller(c), c.Param("id"), req.Note)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Approve Return",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) Reject(c *gin.Context) {
+	var req ResolveRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	data, appErr := h.returnService.Reject(c.Request.Context(), middleware.Caller(c), c.Param("id"), req.Note)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Reject Return",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) Receive(c *gin.Context) {
+	var req ReceiveRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	data, appErr := h.returnService.Receive(c.Request.Context(), middleware.Caller(c), c.Param("id"), req.Restock)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Receive Return",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ReturnHandler) Refund(c *gin.Context) {
+	var req RefundRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	data, refund, appErr := h.returnService.Refund(c.Request.Context(), middleware.Caller(c), c.Param("id"), req.Amount)
+	if appErr != nil {
+		// A declined refund is still recorded; hand it back so the client
+		// can see why.
+		if refund.ID != "" {
+			appErr.Details = NewRefundResponse(refund)
+		}
+		c.Error(appErr)
+		return
+	}
+
+	result := RefundResultResponse{
+		Return: NewResponse(data),
+		Refund: NewRefundResponse(refund),
+	}
+
+	// The gateway could not say how the refund ended; it is settled later
+	// and shows on the return once it has.
+	if refund.Status == payment.StatusPending {
+		status, res := response.Accepted("Refund Is Being Processed", result)
+		c.JSON(status, res)
+		return
+	}
+
+	status, res := response.Success("Success Refund Return", result)
+	c.JSON(status, res)
+}
+
+// bindOptionalJSON binds the body into req, allowing it to be empty. It
+// reports the error and returns false when the body is invalid.
+func bindOptionalJSON(c *gin.Context, req any) bool {
+	if err := c.ShouldBindJSON(req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return false
+	}
+
+	return true
+}
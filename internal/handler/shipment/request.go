@@ -0,0 +1,33 @@
+package shipment
+
+import (
+	"mini-ecommerce/internal/domain/shipment"
+	"time"
+)
+
+// CreateRequest ships items of the order; leave items empty to ship
+// everything not shipped yet. shipped_at defaults to now.
+type CreateRequest struct {
+	Carrier        string        `json:"carrier" binding:"required,max=50"`
+	TrackingNumber string        `json:"tracking_number" binding:"required,max=100"`
+	ShippedAt      *time.Time    `json:"shipped_at"`
+	Items          []ItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+type ItemRequest struct {
+	OrderItemID int `json:"order_item_id" binding:"required"`
+	Quantity    int `json:"quantity" binding:"required,min=1"`
+}
+
+type UpdateRequest struct {
+	Carrier        *string `json:"carrier,omitempty" binding:"omitempty,min=1,max=50"`
+	TrackingNumber *string `json:"tracking_number,omitempty" binding:"omitempty,min=1,max=100"`
+}
+
+// EventRequest records tracking progress; occurred_at defaults to now.
+type EventRequest struct {
+	Status      shipment.Status `json:"status" binding:"required,oneof=in_transit delivered"`
+	Description string          `json:"description" binding:"max=255"`
+	Location    string          `json:"location" binding:"max=100"`
+	OccurredAt  *time.Time      `json:"occurred_at"`
+}
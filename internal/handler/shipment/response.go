@@ -0,0 +1,62 @@
+package shipment
+
+import (
+	"mini-ecommerce/internal/domain/shipment"
+	"time"
+)
+
+type Response struct {
+	ID             string          `json:"id"`
+	OrderID        int             `json:"order_id"`
+	Carrier        string          `json:"carrier"`
+	TrackingNumber string          `json:"tracking_number"`
+	Status         shipment.Status `json:"status"`
+	ShippedAt      time.Time       `json:"shipped_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	Items          []ItemResponse  `json:"items"`
+	Timeline       []EventResponse `json:"timeline"`
+}
+
+type ItemResponse struct {
+	OrderItemID int `json:"order_item_id"`
+	Quantity    int `json:"quantity"`
+}
+
+type EventResponse struct {
+	Status      shipment.Status `json:"status"`
+	Description string          `json:"description,omitempty"`
+	Location    string          `json:"location,omitempty"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+}
+
+func NewResponse(data shipment.Shipment) Response {
+	itemResponses := []ItemResponse{}
+	for _, item := range data.Items {
+		itemResponses = append(itemResponses, ItemResponse{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	eventResponses := []EventResponse{}
+	for _, event := range data.Events {
+		eventResponses = append(eventResponses, EventResponse{
+			Status:      event.Status,
+			Description: event.Description,
+			Location:    event.Location,
+			OccurredAt:  event.OccurredAt,
+		})
+	}
+
+	return Response{
+		ID:             data.ID,
+		OrderID:        data.OrderID,
+		Carrier:        data.Carrier,
+		TrackingNumber: data.TrackingNumber,
+		Status:         data.Status,
+		ShippedAt:      data.ShippedAt,
+		DeliveredAt:    data.DeliveredAt,
+		Items:          itemResponses,
+		Timeline:       eventResponses,
+	}
+}
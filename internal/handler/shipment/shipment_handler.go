@@ -0,0 +1,175 @@
+package shipment
+
+import (
+	"errors"
+	"mini-ecommerce/internal/domain/shipment"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/middleware"
+	"mini-ecommerce/internal/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShipmentHandler struct {
+	shipmentService shipment.Service
+}
+
+func NewHandler(shipmentService shipment.Service) *ShipmentHandler {
+	return &ShipmentHandler{shipmentService: shipmentService}
+}
+
+func (h *ShipmentHandler) Create(c *gin.Context) {
+	orderId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Order id must be a number"),
+		))
+		return
+	}
+
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	data := shipment.Shipment{
+		OrderID:        orderId,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+	}
+	if req.ShippedAt != nil {
+		data.ShippedAt = *req.ShippedAt
+	}
+	for _, item := range req.Items {
+		data.Items = append(data.Items, shipment.Item{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	if appErr := h.shipmentService.Create(c.Request.Context(), middleware.Caller(c), &data); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Created(
+		"Success Create Shipment",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ShipmentHandler) Get(c *gin.Context) {
+	data, appErr := h.shipmentService.Get(c.Request.Context(), middleware.Caller(c), c.Param("id"))
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Get Shipment",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ShipmentHandler) GetByOrder(c *gin.Context) {
+	orderId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("Order id must be a number"),
+		))
+		return
+	}
+
+	shipments, appErr := h.shipmentService.GetByOrderId(c.Request.Context(), middleware.Caller(c), orderId)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	shipmentResponses := []Response{}
+	for _, data := range shipments {
+		shipmentResponses = append(shipmentResponses, NewResponse(data))
+	}
+
+	status, res := response.Success(
+		"Success Get Shipments",
+		shipmentResponses,
+	)
+	c.JSON(status, res)
+}
+
+func (h *ShipmentHandler) Update(c *gin.Context) {
+	var req UpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	shipmentUpdate := shipment.Update{
+		ID:             c.Param("id"),
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+	}
+	data, appErr := h.shipmentService.Update(c.Request.Context(), middleware.Caller(c), &shipmentUpdate)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Update Shipment",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
+
+func (h *ShipmentHandler) AddEvent(c *gin.Context) {
+	var req EventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	event := shipment.Event{
+		ShipmentID:  c.Param("id"),
+		Status:      req.Status,
+		Description: req.Description,
+		Location:    req.Location,
+	}
+	if req.OccurredAt != nil {
+		event.OccurredAt = *req.OccurredAt
+	}
+
+	data, appErr := h.shipmentService.AddEvent(c.Request.Context(), middleware.Caller(c), &event)
+	if appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.Success(
+		"Success Add Shipment Event",
+		NewResponse(data),
+	)
+	c.JSON(status, res)
+}
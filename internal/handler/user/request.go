@@ -1,5 +1,7 @@
 package user
 
+import "mini-ecommerce/internal/domain/user"
+
 type CreateRequest struct {
 	Name     string `json:"name" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email,max=50"`
@@ -13,7 +15,6 @@ type UpdateRequest struct {
 	NewPassword *string `json:"new_password" binding:"omitempty,min=4"`
 }
 
-type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email,max=50"`
-	Password string `json:"password" binding:"required,min=4"`
+type UpdateRoleRequest struct {
+	Role user.Role `json:"role" binding:"required,oneof=admin customer"`
 }
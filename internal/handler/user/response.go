@@ -5,10 +5,3 @@ type Response struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
-
-type LoginResponse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Email       string `json:"email"`
-	AccessToken string `json:"access_token"`
-}
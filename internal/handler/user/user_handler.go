@@ -1,10 +1,12 @@
 package user
 
 import (
+	"errors"
 	"mini-ecommerce/internal/domain/user"
 	"mini-ecommerce/internal/helper"
 	"mini-ecommerce/internal/response"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -49,39 +51,6 @@ func (h *UserHandler) Create(c *gin.Context) {
 	c.JSON(status, res)
 }
 
-func (h *UserHandler) GetByEmail(c *gin.Context) {
-	var req LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.Error(helper.NewAppError(
-			http.StatusBadRequest,
-			"Invalid Request Body",
-			err,
-		))
-		return
-	}
-
-	login := user.Login{
-		Email:    req.Email,
-		Password: req.Password,
-	}
-	result, accessToken, appErr := h.userService.GetByEmail(c.Request.Context(), login)
-	if appErr != nil {
-		c.Error(appErr)
-		return
-	}
-
-	status, res := response.Success(
-		"Success Get User",
-		LoginResponse{
-			ID:          result.ID,
-			Name:        result.Name,
-			Email:       result.Email,
-			AccessToken: accessToken,
-		},
-	)
-	c.JSON(status, res)
-}
-
 func (h *UserHandler) Update(c *gin.Context) {
 	userId := c.MustGet("user_id").(int)
 
@@ -129,3 +98,33 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	status, res := response.SuccessNoContent("Success Delete User")
 	c.JSON(status, res)
 }
+
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	userId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			errors.New("User id must be a number"),
+		))
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request Body",
+			err,
+		))
+		return
+	}
+
+	if appErr := h.userService.UpdateRole(c.Request.Context(), userId, req.Role); appErr != nil {
+		c.Error(appErr)
+		return
+	}
+
+	status, res := response.SuccessNoContent("Success Update User Role")
+	c.JSON(status, res)
+}
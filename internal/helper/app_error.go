@@ -4,6 +4,8 @@ type AppError struct {
 	StatusCode int
 	Message    string
 	Err        error
+	// Details, when set, is sent as the response error instead of Err's message.
+	Details any
 }
 
 func (e *AppError) Error() string {
@@ -1,20 +1,40 @@
 package helper
 
 import (
-	"os"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
+var (
+	jwtKey          []byte
+	accessTokenTTL  = 10 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// ConfigureTokens sets the signing key and lifetimes used for tokens. It must
+// be called once at startup, before any token is issued or parsed.
+func ConfigureTokens(secret string, accessTTL time.Duration, refreshTTL time.Duration) {
+	jwtKey = []byte(secret)
+	accessTokenTTL = accessTTL
+	refreshTokenTTL = refreshTTL
+}
 
-func GenerateAccessToken(id int, name string, email string) (string, error) {
-	expiration := time.Now().Add(10 * time.Minute).Unix()
+func RefreshTokenTTL() time.Duration {
+	return refreshTokenTTL
+}
+
+func GenerateAccessToken(id int, name string, email string, role string) (string, error) {
+	expiration := time.Now().Add(accessTokenTTL).Unix()
 	claims := jwt.MapClaims{
 		"id":    id,
 		"name":  name,
 		"email": email,
+		"role":  role,
 		"exp":   expiration,
 	}
 
@@ -38,3 +58,20 @@ func ParseToken(accessToken string) (jwt.MapClaims, error) {
 
 	return claims, nil
 }
+
+// GenerateRefreshToken returns an opaque random token for the client and the
+// hash that is stored in place of it.
+func GenerateRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	refreshToken := base64.RawURLEncoding.EncodeToString(b)
+	return refreshToken, HashRefreshToken(refreshToken), nil
+}
+
+func HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -11,5 +11,67 @@ var ErrUserNotFound = errors.New("User not found")
 var ErrUserInvalid = errors.New("Invalid email or password")
 var ErrCartNotFound = errors.New("Cart not found")
 var ErrCartItemNotFound = errors.New("Cart Item not found")
-var ErrOrderNotFound = errors.New("Cart Order found")
+var ErrOrderNotFound = errors.New("Order not found")
 var ErrProductInsufficientStock = errors.New("Insufficient stock for product")
+var ErrPaymentNotFound = errors.New("Payment not found")
+var ErrPaymentFailed = errors.New("Payment was declined by the provider")
+var ErrOrderNotPayable = errors.New("Only pending orders can be paid")
+var ErrPaymentInProgress = errors.New("A payment for this order is already in progress")
+var ErrOrderInvalidStatus = errors.New("Unknown order status")
+var ErrOrderInvalidTransition = errors.New("Order status transition is not allowed")
+var ErrCartEmpty = errors.New("Cart is empty")
+var ErrAdminAlreadyExists = errors.New("An admin user already exists")
+var ErrAdminLast = errors.New("The last admin cannot be made a customer")
+var ErrRefreshTokenInvalid = errors.New("Refresh token is invalid or expired")
+var ErrRefreshTokenReused = errors.New("Refresh token was already used; all sessions on this device were revoked")
+var ErrOrderEmpty = errors.New("Order must contain at least one item")
+var ErrCurrencyMismatch = errors.New("Items priced in different currencies cannot be combined")
+var ErrCurrencyUnsupported = errors.New("Currency is not supported")
+var ErrSearchQueryEmpty = errors.New("Search query must contain at least one letter or digit")
+var ErrIdempotencyKeyInvalid = errors.New("Idempotency-Key must be between 1 and 255 characters")
+var ErrIdempotencyKeyMismatch = errors.New("Idempotency-Key was already used for a different request")
+var ErrIdempotencyKeyInProgress = errors.New("A request with this Idempotency-Key is still being processed")
+var ErrVariantNotFound = errors.New("Product variant not found")
+var ErrVariantAlreadyExists = errors.New("A variant with the same SKU or options already exists")
+var ErrVariantRequired = errors.New("Product has several variants; variant_id is required")
+var ErrVariantInUse = errors.New("Variant has been ordered and cannot be deleted")
+var ErrProductInUse = errors.New("Product has been ordered and cannot be deleted")
+var ErrProductCurrencyOverridden = errors.New("Variant prices override the product price; remove them before changing its currency")
+var ErrProductLastVariant = errors.New("A product must keep at least one variant")
+var ErrProductStockPerVariant = errors.New("Stock of a product with several variants is set per variant")
+var ErrImageNotFound = errors.New("Product image not found")
+var ErrImageUnsupported = errors.New("Image must be a JPEG, PNG or GIF")
+var ErrImageTooLarge = errors.New("Image file is too large")
+var ErrImageDimensions = errors.New("Image dimensions are too large")
+var ErrImageOrderInvalid = errors.New("Image order must list every image of the product exactly once")
+var ErrCategoryParentNotFound = errors.New("Parent category was not found")
+var ErrCategoryCycle = errors.New("A category cannot be moved below itself or one of its subcategories")
+var ErrCategoryNotEmpty = errors.New("Category still has subcategories or products")
+var ErrCouponNotFound = errors.New("Coupon not found")
+var ErrCouponAlreadyExists = errors.New("A coupon with the same code already exists")
+var ErrCouponInvalid = errors.New("Coupon settings do not match its kind")
+var ErrCouponNotActive = errors.New("Coupon is not valid at this time")
+var ErrCouponUsageExceeded = errors.New("Coupon has reached its usage limit")
+var ErrCouponUserLimitExceeded = errors.New("Coupon has already been used the maximum number of times by this user")
+var ErrCouponMinimumNotMet = errors.New("Order subtotal is below the coupon minimum")
+var ErrCouponNotApplicable = errors.New("Coupon does not apply to any item in the order")
+var ErrShippingRateNotFound = errors.New("Shipping rate not found")
+var ErrShippingRateAlreadyExists = errors.New("A shipping rate for the same region, currency and weight already exists")
+var ErrTaxRateNotFound = errors.New("Tax rate not found")
+var ErrTaxRateAlreadyExists = errors.New("A tax rate for the same region and category already exists")
+var ErrAddressNotFound = errors.New("Address not found")
+var ErrAddressRequired = errors.New("Add a shipping address or choose one with address_id")
+var ErrShipmentNotFound = errors.New("Shipment not found")
+var ErrShipmentNotAllowed = errors.New("Only paid or shipped orders can be shipped")
+var ErrShipmentNothingToShip = errors.New("Every item of the order has already been shipped")
+var ErrShipmentItemsInvalid = errors.New("Shipment items must belong to the order and not exceed the quantity left to ship")
+var ErrShipmentInvalidStatus = errors.New("Shipment status can only move forward")
+var ErrReturnNotFound = errors.New("Return not found")
+var ErrReturnNotAllowed = errors.New("Only paid, shipped or delivered orders can be returned")
+var ErrReturnItemsInvalid = errors.New("Return items must belong to the order and not exceed the quantity not yet returned")
+var ErrReturnInvalidTransition = errors.New("Invalid return status transition")
+var ErrRefundAmountInvalid = errors.New("Refund amount must be positive and not exceed what is left to refund on the returned items")
+var ErrRefundNoPayment = errors.New("Order has no successful payment to refund")
+var ErrRefundFailed = errors.New("Refund was declined by the provider")
+var ErrRefundInProgress = errors.New("A refund for this return is already in progress")
+var ErrRefundNotFound = errors.New("Refund not found")
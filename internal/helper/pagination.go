@@ -0,0 +1,56 @@
+package helper
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageQuery is embedded in list request structs to bind ?page= and ?limit=.
+type PageQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (q PageQuery) PageRequest() PageRequest {
+	page := PageRequest{Page: q.Page, Limit: q.Limit}
+	if page.Page < 1 {
+		page.Page = 1
+	}
+	if page.Limit < 1 {
+		page.Limit = DefaultPageLimit
+	}
+	if page.Limit > MaxPageLimit {
+		page.Limit = MaxPageLimit
+	}
+	return page
+}
+
+type PageRequest struct {
+	Page  int
+	Limit int
+}
+
+func (p PageRequest) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
+
+type Pagination struct {
+	Page       int
+	Limit      int
+	TotalItems int
+	TotalPages int
+}
+
+func NewPagination(page PageRequest, totalItems int) Pagination {
+	totalPages := 0
+	if page.Limit > 0 {
+		totalPages = (totalItems + page.Limit - 1) / page.Limit
+	}
+
+	return Pagination{
+		Page:       page.Page,
+		Limit:      page.Limit,
+		TotalItems: totalItems,
+		TotalPages: totalPages,
+	}
+}
@@ -0,0 +1,21 @@
+package helper
+
+import "sync/atomic"
+
+// Readiness records whether the process should still receive new traffic.
+// It starts ready and flips once, when shutdown begins.
+type Readiness struct {
+	draining atomic.Bool
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+func (r *Readiness) StartDraining() {
+	r.draining.Store(true)
+}
+
+func (r *Readiness) IsDraining() bool {
+	return r.draining.Load()
+}
@@ -24,7 +24,14 @@ func NewTransaction(db *pgxpool.Pool) *Transaction {
 	return &Transaction{db: db}
 }
 
+// ExecTx runs fn inside a database transaction. When ctx already carries a
+// transaction, fn joins it instead of opening a new one, so services can be
+// composed without splitting their work across transactions.
 func (s *Transaction) ExecTx(ctx context.Context, fn func(context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
 	tx, err := s.db.Begin(ctx)
 	if err != nil {
 		return err
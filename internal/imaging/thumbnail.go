@@ -0,0 +1,127 @@
+// Package imaging decodes uploaded pictures and derives thumbnails from them
+// using only the standard library.
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+
+	// Register the formats image.Decode understands.
+	_ "image/gif"
+	_ "image/png"
+)
+
+// MaxPixels bounds the decoded size of an upload, so a small file that
+// expands into a huge bitmap cannot exhaust memory.
+const MaxPixels = 40_000_000
+
+// formats maps the sniffed content types to the extension they are stored with.
+var formats = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Decoded is an uploaded image together with what was learned about it.
+type Decoded struct {
+	Image       image.Image
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Decode sniffs the content type of data rather than trusting the client and
+// decodes it when it is a supported format.
+func Decode(data []byte) (Decoded, error) {
+	contentType := http.DetectContentType(data)
+	extension, ok := formats[contentType]
+	if !ok {
+		return Decoded{}, helper.ErrImageUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Decoded{}, helper.ErrImageUnsupported
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return Decoded{}, helper.ErrImageDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Decoded{}, helper.ErrImageUnsupported
+	}
+
+	return Decoded{
+		Image:       img,
+		ContentType: contentType,
+		Extension:   extension,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
+
+// Thumbnail scales img down to fit within size x size, keeping its aspect
+// ratio, by averaging the source pixels each thumbnail pixel covers.
+// Transparent areas are flattened onto white since the result is a JPEG.
+// Images already small enough keep their dimensions.
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			// The channels are alpha premultiplied, so adding the missing
+			// coverage in white composites the pixel onto a white background.
+			white := 0xffff*n - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + white) / n >> 8),
+				G: uint8((g + white) / n >> 8),
+				B: uint8((b + white) / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
+
+// EncodeJPEG writes img as a JPEG suitable for thumbnails.
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 82})
+}
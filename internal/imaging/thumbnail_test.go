@@ -0,0 +1,38 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnailKeepsAspectRatio(t *testing.T) {
+	tests := []struct {
+		width, height int
+		wantW, wantH  int
+	}{
+		{width: 800, height: 400, wantW: 200, wantH: 100},
+		{width: 400, height: 800, wantW: 100, wantH: 200},
+		{width: 1000, height: 1, wantW: 200, wantH: 1},
+		{width: 120, height: 80, wantW: 120, wantH: 80},
+	}
+
+	for _, test := range tests {
+		thumbnail := Thumbnail(image.NewRGBA(image.Rect(0, 0, test.width, test.height)), 200)
+		if got := thumbnail.Bounds(); got.Dx() != test.wantW || got.Dy() != test.wantH {
+			t.Errorf("%dx%d: got %dx%d, want %dx%d", test.width, test.height, got.Dx(), got.Dy(), test.wantW, test.wantH)
+		}
+	}
+}
+
+func TestThumbnailAveragesAndFlattensOntoWhite(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 0, G: 0, B: 0, A: 0xff})
+	// Fully transparent, so it counts as white.
+	src.SetRGBA(1, 0, color.RGBA{})
+
+	got := Thumbnail(src, 1).RGBAAt(0, 0)
+	if got.A != 0xff || got.R < 0x7e || got.R > 0x80 || got.R != got.G || got.G != got.B {
+		t.Errorf("got %v, want opaque mid grey", got)
+	}
+}
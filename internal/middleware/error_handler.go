@@ -13,40 +13,50 @@ func ErrorHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
-		if len(c.Errors) == 0 {
+		// A middleware further in, such as Idempotency, may already have
+		// rendered the errors.
+		if len(c.Errors) == 0 || c.Writer.Written() {
 			return
 		}
 
-		for _, ginErr := range c.Errors {
-			var appErr *helper.AppError
+		writeErrors(c)
+	}
+}
 
-			if errors.As(ginErr, &appErr) {
-				var detail any
-				if appErr.Err != nil {
-					detail = appErr.Err.Error()
-				}
+// writeErrors renders the first AppError in c.Errors, or a generic internal
+// server error when there is none.
+func writeErrors(c *gin.Context) {
+	for _, ginErr := range c.Errors {
+		var appErr *helper.AppError
+
+		if errors.As(ginErr, &appErr) {
+			var detail any
+			if appErr.Details != nil {
+				detail = appErr.Details
+			} else if appErr.Err != nil {
+				detail = appErr.Err.Error()
+			}
 
-				status, res := response.Error(
-					appErr.Message,
-					detail,
-					appErr.StatusCode,
-				)
+			status, res := response.Error(
+				appErr.Message,
+				detail,
+				appErr.StatusCode,
+			)
 
-				c.JSON(status, res)
-				c.Abort()
-				return
-			}
+			c.JSON(status, res)
+			c.Abort()
+			return
 		}
+	}
 
-		lastErr := c.Errors.Last().Err
+	lastErr := c.Errors.Last().Err
 
-		status, res := response.Error(
-			"Internal Server Error",
-			lastErr.Error(),
-			http.StatusInternalServerError,
-		)
+	status, res := response.Error(
+		"Internal Server Error",
+		lastErr.Error(),
+		http.StatusInternalServerError,
+	)
 
-		c.JSON(status, res)
-		c.Abort()
-	}
+	c.JSON(status, res)
+	c.Abort()
 }
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"mini-ecommerce/internal/domain/idempotency"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Idempotency makes a route safe to retry. The first request carrying an
+// Idempotency-Key header runs normally and its response is stored for the
+// calling user; a retry with the same key and body gets that response back,
+// marked with Idempotent-Replayed, without running the handler again. Reusing
+// a key for a different request is rejected. Requests without the header are
+// passed through untouched.
+//
+// Responses with a 5xx status are not stored, so those retries run again.
+// It must run after JWTAuth.
+func Idempotency(idempotencyService idempotency.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.Error(helper.NewAppError(
+				http.StatusBadRequest,
+				"Invalid Request Body",
+				err,
+			))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userId := c.MustGet("user_id").(int)
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.Path, body)
+
+		record, appErr := idempotencyService.Begin(c.Request.Context(), userId, key, fingerprint)
+		if appErr != nil {
+			c.Error(appErr)
+			c.Abort()
+			return
+		}
+
+		if record.Completed() {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", record.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Only a request that failed on the server side is released for the
+		// retry to run again. Once the handler has answered otherwise its work
+		// is committed, so the key stays claimed even when storing the
+		// response fails; the retry then sees the request as in progress
+		// instead of repeating it.
+		release := true
+		defer func() {
+			if !release {
+				return
+			}
+			// The request context may already be cancelled by now.
+			if appErr := idempotencyService.Release(context.Background(), userId, key); appErr != nil {
+				log.Printf("[IDEMPOTENCY] release %q for user %d : %v", key, userId, appErr)
+			}
+		}()
+
+		c.Next()
+
+		// Render errors here rather than in ErrorHandler so the stored body is
+		// the one the client saw.
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			writeErrors(c)
+		}
+
+		statusCode := c.Writer.Status()
+		if statusCode >= http.StatusInternalServerError {
+			return
+		}
+		release = false
+
+		if appErr := idempotencyService.Complete(context.Background(), userId, key, statusCode, recorder.body.Bytes()); appErr != nil {
+			log.Printf("[IDEMPOTENCY] store %q for user %d : %v", key, userId, appErr)
+		}
+	}
+}
+
+func requestFingerprint(method string, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of everything written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(data string) (int, error) {
+	r.body.WriteString(data)
+	return r.ResponseWriter.WriteString(data)
+}
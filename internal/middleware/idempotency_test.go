@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"context"
+	"mini-ecommerce/internal/domain/idempotency"
+	"mini-ecommerce/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	base := requestFingerprint("POST", "/api/orders", []byte(`{"items":[]}`))
+
+	if again := requestFingerprint("POST", "/api/orders", []byte(`{"items":[]}`)); again != base {
+		t.Errorf("same request fingerprinted as %s and %s", base, again)
+	}
+
+	for name, other := range map[string]string{
+		"method": requestFingerprint("PUT", "/api/orders", []byte(`{"items":[]}`)),
+		"path":   requestFingerprint("POST", "/api/payments", []byte(`{"items":[]}`)),
+		"body":   requestFingerprint("POST", "/api/orders", []byte(`{"items":[1]}`)),
+		// The separators keep a shifted boundary from colliding.
+		"boundary": requestFingerprint("POST", "/api/orders{", []byte(`"items":[]}`)),
+	} {
+		if other == base {
+			t.Errorf("different %s gave the same fingerprint", name)
+		}
+	}
+}
+
+const testUserId = 7
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotencyRouter(newIdempotencyService(time.Minute), func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+
+	first := send(r, "key-1", `{"amount":1}`)
+	second := send(r, "key-1", `{"amount":1}`)
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls.Load())
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("replayed %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("replay is not marked Idempotent-Replayed")
+	}
+	if first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("first response is marked Idempotent-Replayed")
+	}
+}
+
+func TestIdempotencyRejectsKeyReusedForDifferentBody(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotencyRouter(newIdempotencyService(time.Minute), func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	send(r, "key-1", `{"amount":1}`)
+	res := send(r, "key-1", `{"amount":2}`)
+
+	if res.Code != http.StatusConflict {
+		t.Errorf("status %d, want %d", res.Code, http.StatusConflict)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyRejectsRequestInProgress(t *testing.T) {
+	entered := make(chan struct{})
+	finish := make(chan struct{})
+	var calls atomic.Int32
+	r := idempotencyRouter(newIdempotencyService(time.Minute), func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			close(entered)
+			<-finish
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		send(r, "key-1", `{}`)
+	}()
+	<-entered
+
+	res := send(r, "key-1", `{}`)
+	close(finish)
+	wg.Wait()
+
+	if res.Code != http.StatusConflict {
+		t.Errorf("status %d, want %d", res.Code, http.StatusConflict)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	var calls atomic.Int32
+	r := idempotencyRouter(newIdempotencyService(time.Minute), func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	send(r, "key-1", `{}`)
+	res := send(r, "key-1", `{}`)
+
+	if res.Code != http.StatusCreated || calls.Load() != 2 {
+		t.Errorf("retry got %d after %d runs, want %d after 2", res.Code, calls.Load(), http.StatusCreated)
+	}
+}
+
+func TestIdempotencyTakesOverKeyAfterLease(t *testing.T) {
+	const lease = 50 * time.Millisecond
+	idempotencyService := newIdempotencyService(lease)
+
+	var calls atomic.Int32
+	r := idempotencyRouter(idempotencyService, func(c *gin.Context) {
+		calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	// A request whose process died after claiming the key.
+	fingerprint := requestFingerprint(http.MethodPost, "/api/payments", []byte(`{}`))
+	if _, appErr := idempotencyService.Begin(context.Background(), testUserId, "key-1", fingerprint); appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	if res := send(r, "key-1", `{}`); res.Code != http.StatusConflict {
+		t.Fatalf("within the lease got %d, want %d", res.Code, http.StatusConflict)
+	}
+
+	time.Sleep(2 * lease)
+
+	if res := send(r, "key-1", `{"other":true}`); res.Code != http.StatusConflict {
+		t.Errorf("different request after the lease got %d, want %d", res.Code, http.StatusConflict)
+	}
+	if res := send(r, "key-1", `{}`); res.Code != http.StatusCreated {
+		t.Errorf("same request after the lease got %d, want %d", res.Code, http.StatusCreated)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", calls.Load())
+	}
+}
+
+func newIdempotencyService(lease time.Duration) idempotency.Service {
+	return service.NewIdempotency(newMemoryIdempotency(), time.Hour, lease)
+}
+
+// idempotencyRouter serves handler at POST /api/payments behind Idempotency,
+// as user testUserId.
+func idempotencyRouter(idempotencyService idempotency.Service, handler gin.HandlerFunc) *gin.Engine {
+	r := gin.New()
+	r.Use(ErrorHandler(), func(c *gin.Context) { c.Set("user_id", testUserId) })
+	r.POST("/api/payments", Idempotency(idempotencyService), handler)
+	return r
+}
+
+func send(r *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/payments", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	return res
+}
+
+// memoryIdempotency keeps records in memory, following the contract of
+// idempotency.Repository.
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	records map[memoryKey]idempotency.Record
+}
+
+type memoryKey struct {
+	userId int
+	key    string
+}
+
+func newMemoryIdempotency() *memoryIdempotency {
+	return &memoryIdempotency{records: map[memoryKey]idempotency.Record{}}
+}
+
+func (m *memoryIdempotency) Reserve(ctx context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	stored, ok := m.records[memoryKey{record.UserID, record.Key}]
+	takeOver := !ok || !stored.ExpiresAt.After(now) ||
+		(!stored.Completed() && !stored.LockedUntil.After(now) && stored.Fingerprint == record.Fingerprint)
+	if !takeOver {
+		return stored, false, nil
+	}
+
+	m.records[memoryKey{record.UserID, record.Key}] = record
+	return record, true, nil
+}
+
+func (m *memoryIdempotency) Complete(ctx context.Context, userId int, key string, statusCode int, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record, ok := m.records[memoryKey{userId, key}]
+	if !ok {
+		return nil
+	}
+	record.StatusCode = statusCode
+	record.Body = append([]byte(nil), body...)
+	m.records[memoryKey{userId, key}] = record
+	return nil
+}
+
+func (m *memoryIdempotency) Release(ctx context.Context, userId int, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if record, ok := m.records[memoryKey{userId, key}]; ok && !record.Completed() {
+		delete(m.records, memoryKey{userId, key})
+	}
+	return nil
+}
+
+func (m *memoryIdempotency) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+var _ idempotency.Repository = (*memoryIdempotency)(nil)
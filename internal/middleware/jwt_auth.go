@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"mini-ecommerce/internal/domain/user"
 	"mini-ecommerce/internal/helper"
 	"net/http"
 	"strings"
@@ -48,6 +49,13 @@ func JWTAuth() gin.HandlerFunc {
 
 		id := claims["id"].(float64)
 		c.Set("user_id", int(id))
+
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = string(user.RoleCustomer)
+		}
+		c.Set("role", user.Role(role))
+
 		c.Next()
 	}
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole must run after JWTAuth. It lets the request through only when
+// the caller's role is one of roles.
+func RequireRole(roles ...user.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(helper.NewAppError(
+			http.StatusForbidden,
+			"Forbidden",
+			errors.New("You do not have permission to access this resource"),
+		))
+		c.Abort()
+	}
+}
+
+// Caller returns the user JWTAuth authenticated for the request.
+func Caller(c *gin.Context) user.Caller {
+	return user.Caller{
+		ID:   c.MustGet("user_id").(int),
+		Role: c.MustGet("role").(user.Role),
+	}
+}
@@ -0,0 +1,183 @@
+// Package money represents amounts as integer minor units of an ISO 4217
+// currency so sums and products never drift the way float64 does.
+package money
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// exponents maps the supported ISO 4217 codes to their number of minor unit
+// digits.
+var exponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"JPY": 0,
+	"KRW": 0,
+}
+
+type Money struct {
+	// Amount is in minor units, e.g. cents for USD.
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+func IsSupportedCurrency(currency string) bool {
+	_, ok := exponents[strings.ToUpper(currency)]
+	return ok
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency
+}
+
+// Add panics when the currencies differ; callers check SameCurrency first
+// when the inputs come from outside.
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}
+}
+
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}
+}
+
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// Percent returns basisPoints/10000 of m rounded half away from zero, so
+// 1250 basis points is 12.5%. It is the single rounding rule used for tax and
+// discounts.
+func (m Money) Percent(basisPoints int64) Money {
+	return Money{Amount: divRound(m.Amount*basisPoints, 10000), Currency: m.Currency}
+}
+
+func (m Money) Min(other Money) Money {
+	m.mustMatch(other)
+	if other.Amount < m.Amount {
+		return other
+	}
+	return m
+}
+
+// Fraction returns numerator/denominator of m truncated, for numerator
+// between zero and denominator. Like Allocate it cannot overflow.
+func (m Money) Fraction(numerator int, denominator int) Money {
+	return Money{Amount: mulDiv(m.Amount, int64(numerator), int64(denominator)), Currency: m.Currency}
+}
+
+// Allocate splits m across parts in proportion to their amounts. The shares
+// always sum to m, and none exceeds its part while m does not exceed their
+// total. Parts must be non-negative and in m's currency.
+func (m Money) Allocate(parts []Money) []Money {
+	var total int64
+	for _, part := range parts {
+		m.mustMatch(part)
+		total += part.Amount
+	}
+
+	shares := make([]Money, len(parts))
+	var cumulative, allocated int64
+	for i, part := range parts {
+		cumulative += part.Amount
+		next := m.Amount
+		if total != 0 {
+			next = mulDiv(m.Amount, cumulative, total)
+		}
+		shares[i] = Money{Amount: next - allocated, Currency: m.Currency}
+		allocated = next
+	}
+
+	return shares
+}
+
+// String formats the amount in major units, e.g. "12.50 USD".
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.Decimal(), m.Currency)
+}
+
+// Decimal formats the amount in major units without the currency.
+func (m Money) Decimal() string {
+	exponent := exponents[m.Currency]
+	if exponent == 0 {
+		return fmt.Sprintf("%d", m.Amount)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	scale := int64(1)
+	for i := 0; i < exponent; i++ {
+		scale *= 10
+	}
+
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, exponent, amount%scale)
+}
+
+func (m Money) mustMatch(other Money) {
+	if m.Currency != other.Currency {
+		panic(fmt.Sprintf("money: currency mismatch %s and %s", m.Currency, other.Currency))
+	}
+}
+
+// mulDiv returns a*b/c truncated, for b <= c and both non-negative. The
+// product is taken in 128 bits since it overflows int64 on large amounts in
+// currencies such as IDR.
+func mulDiv(a int64, b int64, c int64) int64 {
+	negative := a < 0
+	if negative {
+		a = -a
+	}
+
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	quotient, _ := bits.Div64(hi, lo, uint64(c))
+	if negative {
+		return -int64(quotient)
+	}
+	return int64(quotient)
+}
+
+// divRound divides and rounds half away from zero.
+func divRound(numerator int64, denominator int64) int64 {
+	quotient := numerator / denominator
+	remainder := numerator % denominator
+	if remainder < 0 {
+		remainder = -remainder
+	}
+
+	if remainder*2 >= denominator {
+		if numerator < 0 {
+			quotient--
+		} else {
+			quotient++
+		}
+	}
+
+	return quotient
+}
@@ -0,0 +1,118 @@
+package money
+
+import "testing"
+
+func TestPercentRoundsHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		amount      int64
+		basisPoints int64
+		want        int64
+	}{
+		{amount: 1000, basisPoints: 1250, want: 125},
+		{amount: 5, basisPoints: 1000, want: 1},
+		{amount: 4, basisPoints: 1000, want: 0},
+		{amount: -5, basisPoints: 1000, want: -1},
+		{amount: 0, basisPoints: 1000, want: 0},
+	}
+
+	for _, test := range tests {
+		got := New(test.amount, "USD").Percent(test.basisPoints)
+		if got.Amount != test.want || got.Currency != "USD" {
+			t.Errorf("%d at %d bp = %v, want %d USD", test.amount, test.basisPoints, got, test.want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		parts  []int64
+		want   []int64
+	}{
+		{name: "proportional", amount: 100, parts: []int64{100, 300}, want: []int64{25, 75}},
+		{name: "remainder", amount: 100, parts: []int64{1, 1, 1}, want: []int64{33, 33, 34}},
+		{name: "zero total", amount: 10, parts: []int64{0, 0}, want: []int64{10, 0}},
+		{name: "zero amount", amount: 0, parts: []int64{5, 7}, want: []int64{0, 0}},
+		// Rp 20M discount over a Rp 200M order in minor units overflows
+		// int64 when multiplied naively.
+		{name: "large amounts", amount: 2_000_000_000, parts: []int64{15_000_000_000, 5_000_000_000}, want: []int64{1_500_000_000, 500_000_000}},
+		{name: "negative amount", amount: -100, parts: []int64{1, 3}, want: []int64{-25, -75}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parts := make([]Money, len(test.parts))
+			for i, part := range test.parts {
+				parts[i] = New(part, "USD")
+			}
+
+			shares := New(test.amount, "USD").Allocate(parts)
+			for i, share := range shares {
+				if share.Amount != test.want[i] {
+					t.Errorf("share %d = %d, want %d", i, share.Amount, test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFraction(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      int64
+		numerator   int
+		denominator int
+		want        int64
+	}{
+		{name: "truncates", amount: 100, numerator: 1, denominator: 3, want: 33},
+		{name: "whole", amount: 100, numerator: 3, denominator: 3, want: 100},
+		{name: "none", amount: 100, numerator: 0, denominator: 3, want: 0},
+		{name: "large amount", amount: 1 << 62, numerator: 7, denominator: 8, want: 7 << 59},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := New(test.amount, "USD").Fraction(test.numerator, test.denominator)
+			if got.Amount != test.want {
+				t.Errorf("Fraction(%d, %d) of %d = %d, want %d", test.numerator, test.denominator, test.amount, got.Amount, test.want)
+			}
+		})
+	}
+}
+
+func TestArithmeticPanicsOnCurrencyMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Add across currencies did not panic")
+		}
+	}()
+
+	New(1, "USD").Add(New(1, "EUR"))
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: New(1250, "usd"), want: "12.50 USD"},
+		{money: New(-5, "USD"), want: "-0.05 USD"},
+		{money: New(1500, "JPY"), want: "1500 JPY"},
+	}
+
+	for _, test := range tests {
+		if got := test.money.String(); got != test.want {
+			t.Errorf("String() = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestIsSupportedCurrency(t *testing.T) {
+	if !IsSupportedCurrency("idr") {
+		t.Error("idr is not supported")
+	}
+	if IsSupportedCurrency("XXX") {
+		t.Error("XXX is supported")
+	}
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/address"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type addressRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewAddress(tx *helper.Transaction) address.Repository {
+	return &addressRepositoryImpl{tx: tx}
+}
+
+const addressColumns = "id, user_id, recipient, phone, line1, line2, city, postal_code, region, country, is_default"
+
+const addressSelect = "SELECT " + addressColumns + " FROM addresses"
+
+func scanAddress(row pgx.Row, data *address.Data) error {
+	return row.Scan(
+		&data.ID,
+		&data.UserID,
+		&data.Address.Recipient,
+		&data.Address.Phone,
+		&data.Address.Line1,
+		&data.Address.Line2,
+		&data.Address.City,
+		&data.Address.PostalCode,
+		&data.Address.Region,
+		&data.Address.Country,
+		&data.Default,
+	)
+}
+
+// LockByUserId takes a lock on the user row that does not block foreign keys
+// referencing it.
+func (a *addressRepositoryImpl) LockByUserId(ctx context.Context, userId int) error {
+	db := a.tx.GetTx(ctx)
+	var id int
+	err := db.QueryRow(ctx, "SELECT id FROM users WHERE id = $1 FOR NO KEY UPDATE", userId).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return helper.ErrUserNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (a *addressRepositoryImpl) Create(ctx context.Context, data *address.Data) error {
+	db := a.tx.GetTx(ctx)
+	query := "INSERT INTO addresses (user_id, recipient, phone, line1, line2, city, postal_code, region, country, is_default) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id"
+	return db.QueryRow(
+		ctx,
+		query,
+		data.UserID,
+		data.Address.Recipient,
+		data.Address.Phone,
+		data.Address.Line1,
+		data.Address.Line2,
+		data.Address.City,
+		data.Address.PostalCode,
+		data.Address.Region,
+		data.Address.Country,
+		data.Default,
+	).Scan(&data.ID)
+}
+
+func (a *addressRepositoryImpl) FindById(ctx context.Context, userId int, id string) (address.Data, error) {
+	db := a.tx.GetTx(ctx)
+	var data address.Data
+	if err := scanAddress(db.QueryRow(ctx, addressSelect+" WHERE id = $1 AND user_id = $2", id, userId), &data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return address.Data{}, helper.ErrAddressNotFound
+		}
+		return address.Data{}, err
+	}
+
+	return data, nil
+}
+
+func (a *addressRepositoryImpl) FindByUserId(ctx context.Context, userId int) ([]address.Data, error) {
+	db := a.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, addressSelect+" WHERE user_id = $1 ORDER BY is_default DESC, id DESC", userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []address.Data
+	for rows.Next() {
+		var data address.Data
+		if err := scanAddress(rows, &data); err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, data)
+	}
+
+	return addresses, rows.Err()
+}
+
+func (a *addressRepositoryImpl) FindDefault(ctx context.Context, userId int) (address.Data, error) {
+	db := a.tx.GetTx(ctx)
+	var data address.Data
+	if err := scanAddress(db.QueryRow(ctx, addressSelect+" WHERE user_id = $1 AND is_default", userId), &data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return address.Data{}, helper.ErrAddressNotFound
+		}
+		return address.Data{}, err
+	}
+
+	return data, nil
+}
+
+func (a *addressRepositoryImpl) Update(ctx context.Context, update *address.Update) error {
+	db := a.tx.GetTx(ctx)
+	query := "UPDATE addresses SET recipient = COALESCE($1, recipient), phone = COALESCE($2, phone), line1 = COALESCE($3, line1), " +
+		"line2 = COALESCE($4, line2), city = COALESCE($5, city), postal_code = COALESCE($6, postal_code), region = COALESCE($7, region), " +
+		"country = COALESCE($8, country), is_default = COALESCE($9, is_default), updated_at = NOW() WHERE id = $10 AND user_id = $11 " +
+		"RETURNING recipient, phone, line1, line2, city, postal_code, region, country, is_default"
+	err := db.QueryRow(
+		ctx,
+		query,
+		update.Recipient,
+		update.Phone,
+		update.Line1,
+		update.Line2,
+		update.City,
+		update.PostalCode,
+		update.Region,
+		update.Country,
+		update.Default,
+		update.ID,
+		update.UserID,
+	).Scan(
+		&update.Recipient,
+		&update.Phone,
+		&update.Line1,
+		&update.Line2,
+		&update.City,
+		&update.PostalCode,
+		&update.Region,
+		&update.Country,
+		&update.Default,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return helper.ErrAddressNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (a *addressRepositoryImpl) ClearDefault(ctx context.Context, userId int) error {
+	db := a.tx.GetTx(ctx)
+	_, err := db.Exec(ctx, "UPDATE addresses SET is_default = FALSE, updated_at = NOW() WHERE user_id = $1 AND is_default", userId)
+	return err
+}
+
+func (a *addressRepositoryImpl) PromoteDefault(ctx context.Context, userId int) error {
+	db := a.tx.GetTx(ctx)
+	query := "UPDATE addresses SET is_default = TRUE, updated_at = NOW() WHERE id = (SELECT id FROM addresses WHERE user_id = $1 ORDER BY id DESC LIMIT 1)"
+	_, err := db.Exec(ctx, query, userId)
+	return err
+}
+
+func (a *addressRepositoryImpl) Delete(ctx context.Context, userId int, id string) error {
+	db := a.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "DELETE FROM addresses WHERE id = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrAddressNotFound
+	}
+
+	return nil
+}
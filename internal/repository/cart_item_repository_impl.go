@@ -19,16 +19,16 @@ func NewCartItem(tx *helper.Transaction) cart.ItemRepository {
 
 func (c *cartItemRepositoryImpl) Create(ctx context.Context, item *cart.Item) error {
 	db := c.tx.GetTx(ctx)
-	query := "INSERT INTO cart_items (cart_id, product_id, quantity) VALUES ($1, $2, $3) RETURNING id"
-	err := db.QueryRow(ctx, query, item.CartID, item.ProductID, item.Quantity).Scan(&item.ID)
+	query := "INSERT INTO cart_items (cart_id, product_id, variant_id, quantity) VALUES ($1, $2, $3, $4) RETURNING id"
+	err := db.QueryRow(ctx, query, item.CartID, item.ProductID, item.VariantID, item.Quantity).Scan(&item.ID)
 	return err
 }
 
 func (c *cartItemRepositoryImpl) FindById(ctx context.Context, itemId int) (cart.Item, error) {
 	db := c.tx.GetTx(ctx)
-	query := "SELECT id, cart_id, product_id, quantity FROM cart_items WHERE id = $1"
+	query := "SELECT id, cart_id, product_id, variant_id, quantity FROM cart_items WHERE id = $1"
 	var cartItem cart.Item
-	err := db.QueryRow(ctx, query, itemId).Scan(&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.Quantity)
+	err := db.QueryRow(ctx, query, itemId).Scan(&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.VariantID, &cartItem.Quantity)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -42,7 +42,7 @@ func (c *cartItemRepositoryImpl) FindById(ctx context.Context, itemId int) (cart
 
 func (c *cartItemRepositoryImpl) FindAllByCartId(ctx context.Context, cartId int) ([]cart.Item, error) {
 	db := c.tx.GetTx(ctx)
-	query := "SELECT id, cart_id, product_id, quantity FROM cart_items WHERE cart_id = $1 ORDER BY id"
+	query := "SELECT id, cart_id, product_id, variant_id, quantity FROM cart_items WHERE cart_id = $1 ORDER BY id"
 	rows, err := db.Query(ctx, query, cartId)
 	if err != nil {
 		return nil, err
@@ -52,7 +52,7 @@ func (c *cartItemRepositoryImpl) FindAllByCartId(ctx context.Context, cartId int
 	var cartItems []cart.Item
 	for rows.Next() {
 		var cartItem cart.Item
-		if err := rows.Scan(&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.Quantity); err != nil {
+		if err := rows.Scan(&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.VariantID, &cartItem.Quantity); err != nil {
 			return nil, err
 		}
 		cartItems = append(cartItems, cartItem)
@@ -65,11 +65,11 @@ func (c *cartItemRepositoryImpl) FindAllByCartId(ctx context.Context, cartId int
 	return cartItems, nil
 }
 
-func (c *cartItemRepositoryImpl) FindByCartAndProductId(ctx context.Context, cartId int, productId string) (*cart.Item, error) {
+func (c *cartItemRepositoryImpl) FindByCartAndVariantId(ctx context.Context, cartId int, variantId string) (*cart.Item, error) {
 	db := c.tx.GetTx(ctx)
-	query := "SELECT id, cart_id, product_id, quantity FROM cart_items WHERE cart_id = $1 AND product_id = $2"
+	query := "SELECT id, cart_id, product_id, variant_id, quantity FROM cart_items WHERE cart_id = $1 AND variant_id = $2"
 	cartItem := &cart.Item{}
-	err := db.QueryRow(ctx, query, cartId, productId).Scan(&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.Quantity)
+	err := db.QueryRow(ctx, query, cartId, variantId).Scan(&cartItem.ID, &cartItem.CartID, &cartItem.ProductID, &cartItem.VariantID, &cartItem.Quantity)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -110,3 +110,10 @@ func (c *cartItemRepositoryImpl) Delete(ctx context.Context, itemId int) error {
 
 	return nil
 }
+
+func (c *cartItemRepositoryImpl) DeleteAllByCartId(ctx context.Context, cartId int) error {
+	db := c.tx.GetTx(ctx)
+	query := "DELETE FROM cart_items WHERE cart_id = $1"
+	_, err := db.Exec(ctx, query, cartId)
+	return err
+}
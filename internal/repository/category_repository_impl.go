@@ -8,29 +8,47 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type categoryRepositoryImpl struct {
-	db *pgxpool.Pool
+	tx *helper.Transaction
 }
 
-func NewCategory(db *pgxpool.Pool) category.Repository {
-	return &categoryRepositoryImpl{db: db}
+func NewCategory(tx *helper.Transaction) category.Repository {
+	return &categoryRepositoryImpl{tx: tx}
+}
+
+// categoryColumns reads a category of alias c, with a top-level category's
+// parent as an empty string.
+const categoryColumns = "c.id, c.name, COALESCE(c.parent_id::text, '')"
+
+func scanCategory(row pgx.Row, categoryData *category.Data) error {
+	return row.Scan(
+		&categoryData.ID,
+		&categoryData.Name,
+		&categoryData.ParentID,
+	)
 }
 
 func (c *categoryRepositoryImpl) Create(ctx context.Context, data *category.Data) error {
-	query := "INSERT INTO categories (name) VALUES ($1) RETURNING id"
-	err := c.db.QueryRow(
+	db := c.tx.GetTx(ctx)
+	query := "INSERT INTO categories (name, parent_id) VALUES ($1, NULLIF($2, '')::bigint) RETURNING id"
+	err := db.QueryRow(
 		ctx,
 		query,
 		data.Name,
+		data.ParentID,
 	).Scan(&data.ID)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return helper.ErrCategoryAlreadyExists
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return helper.ErrCategoryAlreadyExists
+			case "23503":
+				return helper.ErrCategoryParentNotFound
+			}
 		}
 		return err
 	}
@@ -39,18 +57,10 @@ func (c *categoryRepositoryImpl) Create(ctx context.Context, data *category.Data
 }
 
 func (c *categoryRepositoryImpl) Find(ctx context.Context, id string) (category.Data, error) {
-	query := "SELECT id, name FROM categories WHERE id = $1"
+	db := c.tx.GetTx(ctx)
+	query := "SELECT " + categoryColumns + " FROM categories c WHERE c.id = $1"
 	var categoryData category.Data
-	err := c.db.QueryRow(
-		ctx,
-		query,
-		id,
-	).Scan(
-		&categoryData.ID,
-		&categoryData.Name,
-	)
-
-	if err != nil {
+	if err := scanCategory(db.QueryRow(ctx, query, id), &categoryData); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return category.Data{}, helper.ErrCategoryNotFound
 		}
@@ -60,22 +70,93 @@ func (c *categoryRepositoryImpl) Find(ctx context.Context, id string) (category.
 	return categoryData, nil
 }
 
-func (c *categoryRepositoryImpl) FindAll(ctx context.Context) ([]category.Data, error) {
-	query := "SELECT id, name FROM categories"
-	rows, err := c.db.Query(ctx, query)
+func (c *categoryRepositoryImpl) FindAll(ctx context.Context, page helper.PageRequest) ([]category.Data, int, error) {
+	db := c.tx.GetTx(ctx)
+	var total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM categories").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + categoryColumns + " FROM categories c ORDER BY c.name, c.id LIMIT $1 OFFSET $2"
+	categories, err := c.findAll(ctx, query, page.Limit, page.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return categories, total, nil
+}
+
+func (c *categoryRepositoryImpl) FindEvery(ctx context.Context) ([]category.Data, error) {
+	return c.findAll(ctx, "SELECT "+categoryColumns+" FROM categories c ORDER BY c.name, c.id")
+}
+
+func (c *categoryRepositoryImpl) FindPath(ctx context.Context, id string) ([]category.Data, error) {
+	query := "WITH RECURSIVE path AS (" +
+		"SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1 " +
+		"UNION ALL " +
+		"SELECT parent.id, parent.parent_id, path.depth + 1 FROM categories parent JOIN path ON parent.id = path.parent_id" +
+		") SELECT " + categoryColumns + " FROM path JOIN categories c ON c.id = path.id ORDER BY path.depth DESC"
+	categories, err := c.findAll(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(categories) == 0 {
+		return nil, helper.ErrCategoryNotFound
+	}
+
+	return categories, nil
+}
+
+func (c *categoryRepositoryImpl) FindDescendantIds(ctx context.Context, id string) ([]string, error) {
+	db := c.tx.GetTx(ctx)
+	query := "WITH RECURSIVE subtree AS (" +
+		"SELECT id FROM categories WHERE id = $1 " +
+		"UNION ALL " +
+		"SELECT child.id FROM categories child JOIN subtree ON child.parent_id = subtree.id" +
+		") SELECT id FROM subtree"
+	rows, err := db.Query(ctx, query, id)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var categories []category.Data
+	ids := []string{}
+	for rows.Next() {
+		var descendantId string
+		if err := rows.Scan(&descendantId); err != nil {
+			return nil, err
+		}
+		ids = append(ids, descendantId)
+	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// LockTree must run inside ExecTx for the lock to outlive the statement. The
+// lock still lets readers through.
+func (c *categoryRepositoryImpl) LockTree(ctx context.Context) error {
+	db := c.tx.GetTx(ctx)
+	_, err := db.Exec(ctx, "LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE")
+	return err
+}
+
+func (c *categoryRepositoryImpl) findAll(ctx context.Context, query string, args ...any) ([]category.Data, error) {
+	db := c.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []category.Data
 	for rows.Next() {
 		var categoryData category.Data
-		if err := rows.Scan(
-			&categoryData.ID,
-			&categoryData.Name,
-		); err != nil {
+		if err := scanCategory(rows, &categoryData); err != nil {
 			return nil, err
 		}
 		categories = append(categories, categoryData)
@@ -89,31 +170,59 @@ func (c *categoryRepositoryImpl) FindAll(ctx context.Context) ([]category.Data,
 }
 
 func (c *categoryRepositoryImpl) Update(ctx context.Context, update *category.Update) error {
-	query := "UPDATE categories SET name = $1, updated_at = NOW() WHERE id = $2 RETURNING id, name"
-	err := c.db.QueryRow(
+	db := c.tx.GetTx(ctx)
+	query := "UPDATE categories c SET name = $1, parent_id = CASE WHEN $2::boolean THEN NULLIF($3, '')::bigint ELSE c.parent_id END, updated_at = NOW() WHERE c.id = $4 RETURNING " + categoryColumns
+
+	moved := update.ParentID != nil
+	parentId := ""
+	if moved {
+		parentId = *update.ParentID
+	}
+
+	var updated category.Data
+	err := scanCategory(db.QueryRow(
 		ctx,
 		query,
 		update.Name,
+		moved,
+		parentId,
 		update.ID,
-	).Scan(
-		&update.ID,
-		&update.Name,
-	)
+	), &updated)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return helper.ErrCategoryNotFound
 		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return helper.ErrCategoryAlreadyExists
+			case "23503":
+				return helper.ErrCategoryParentNotFound
+			case "23514":
+				return helper.ErrCategoryCycle
+			}
+		}
 		return err
 	}
 
+	update.ID = updated.ID
+	update.Name = updated.Name
+	update.ParentID = &updated.ParentID
 	return nil
 }
 
 func (c *categoryRepositoryImpl) Delete(ctx context.Context, id string) error {
+	db := c.tx.GetTx(ctx)
 	query := "DELETE FROM categories WHERE id = $1"
-	cmd, err := c.db.Exec(ctx, query, id)
+	cmd, err := db.Exec(ctx, query, id)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return helper.ErrCategoryNotEmpty
+		}
 		return err
 	}
 
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/helper"
+)
+
+type couponRedemptionRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewCouponRedemption(tx *helper.Transaction) promotion.RedemptionRepository {
+	return &couponRedemptionRepositoryImpl{tx: tx}
+}
+
+func (c *couponRedemptionRepositoryImpl) Create(ctx context.Context, redemption *promotion.Redemption) error {
+	db := c.tx.GetTx(ctx)
+	query := "INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, discount) VALUES ($1, $2, $3, $4) RETURNING id"
+	return db.QueryRow(
+		ctx,
+		query,
+		redemption.CouponID,
+		redemption.UserID,
+		redemption.OrderID,
+		redemption.Discount.Amount,
+	).Scan(&redemption.ID)
+}
+
+func (c *couponRedemptionRepositoryImpl) Count(ctx context.Context, couponId string, userId int) (int, int, error) {
+	db := c.tx.GetTx(ctx)
+	query := "SELECT COUNT(*), COUNT(*) FILTER (WHERE user_id = $2) FROM coupon_redemptions WHERE coupon_id = $1"
+	var total, byUser int
+	if err := db.QueryRow(ctx, query, couponId, userId).Scan(&total, &byUser); err != nil {
+		return 0, 0, err
+	}
+
+	return total, byUser, nil
+}
+
+func (c *couponRedemptionRepositoryImpl) DeleteByOrderId(ctx context.Context, orderId int) error {
+	db := c.tx.GetTx(ctx)
+	_, err := db.Exec(ctx, "DELETE FROM coupon_redemptions WHERE order_id = $1", orderId)
+	return err
+}
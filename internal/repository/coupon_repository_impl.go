@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type couponRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewCoupon(tx *helper.Transaction) promotion.Repository {
+	return &couponRepositoryImpl{tx: tx}
+}
+
+// couponSelect reads coupons of alias c with their scope. The currency is
+// read twice, once for each amount.
+const couponSelect = "SELECT c.id, c.code, c.kind, c.percent_off, c.amount_off, c.currency, c.min_order_amount, c.currency, " +
+	"c.starts_at, c.ends_at, COALESCE(c.usage_limit, 0), COALESCE(c.per_user_limit, 0), c.active, " +
+	"ARRAY(SELECT cp.product_id::text FROM coupon_products cp WHERE cp.coupon_id = c.id ORDER BY cp.product_id), " +
+	"ARRAY(SELECT cc.category_id::text FROM coupon_categories cc WHERE cc.coupon_id = c.id ORDER BY cc.category_id) " +
+	"FROM coupons c"
+
+func scanCoupon(row pgx.Row, coupon *promotion.Coupon) error {
+	return row.Scan(
+		&coupon.ID,
+		&coupon.Code,
+		&coupon.Kind,
+		&coupon.PercentOff,
+		&coupon.AmountOff.Amount,
+		&coupon.AmountOff.Currency,
+		&coupon.MinOrder.Amount,
+		&coupon.MinOrder.Currency,
+		&coupon.StartsAt,
+		&coupon.EndsAt,
+		&coupon.UsageLimit,
+		&coupon.PerUserLimit,
+		&coupon.Active,
+		&coupon.ProductIDs,
+		&coupon.CategoryIDs,
+	)
+}
+
+// Create must run inside ExecTx so a coupon is never stored without its scope.
+func (c *couponRepositoryImpl) Create(ctx context.Context, coupon *promotion.Coupon) error {
+	db := c.tx.GetTx(ctx)
+	query := "INSERT INTO coupons (code, kind, percent_off, amount_off, min_order_amount, currency, starts_at, ends_at, usage_limit, per_user_limit, active) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, 0), NULLIF($10, 0), $11) RETURNING id"
+	err := db.QueryRow(
+		ctx,
+		query,
+		coupon.Code,
+		coupon.Kind,
+		coupon.PercentOff,
+		coupon.AmountOff.Amount,
+		coupon.MinOrder.Amount,
+		coupon.AmountOff.Currency,
+		coupon.StartsAt,
+		coupon.EndsAt,
+		coupon.UsageLimit,
+		coupon.PerUserLimit,
+		coupon.Active,
+	).Scan(&coupon.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return helper.ErrCouponAlreadyExists
+		}
+		return err
+	}
+
+	if len(coupon.ProductIDs) > 0 {
+		if _, err := db.Exec(
+			ctx,
+			"INSERT INTO coupon_products (coupon_id, product_id) SELECT $1, UNNEST($2::bigint[]) ON CONFLICT DO NOTHING",
+			coupon.ID,
+			coupon.ProductIDs,
+		); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return helper.ErrProductNotFound
+			}
+			return err
+		}
+	}
+
+	if len(coupon.CategoryIDs) > 0 {
+		if _, err := db.Exec(
+			ctx,
+			"INSERT INTO coupon_categories (coupon_id, category_id) SELECT $1, UNNEST($2::bigint[]) ON CONFLICT DO NOTHING",
+			coupon.ID,
+			coupon.CategoryIDs,
+		); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return helper.ErrCategoryNotFound
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *couponRepositoryImpl) FindById(ctx context.Context, id string) (promotion.Coupon, error) {
+	return c.find(ctx, couponSelect+" WHERE c.id = $1", id)
+}
+
+func (c *couponRepositoryImpl) FindByCodeForUpdate(ctx context.Context, code string) (promotion.Coupon, error) {
+	return c.find(ctx, couponSelect+" WHERE c.code = $1 FOR UPDATE OF c", code)
+}
+
+func (c *couponRepositoryImpl) find(ctx context.Context, query string, arg any) (promotion.Coupon, error) {
+	db := c.tx.GetTx(ctx)
+	var coupon promotion.Coupon
+	if err := scanCoupon(db.QueryRow(ctx, query, arg), &coupon); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return promotion.Coupon{}, helper.ErrCouponNotFound
+		}
+		return promotion.Coupon{}, err
+	}
+
+	return coupon, nil
+}
+
+func (c *couponRepositoryImpl) FindAll(ctx context.Context, page helper.PageRequest) ([]promotion.Coupon, int, error) {
+	db := c.tx.GetTx(ctx)
+
+	var total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM coupons").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(ctx, couponSelect+" ORDER BY c.created_at DESC, c.id DESC LIMIT $1 OFFSET $2", page.Limit, page.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var coupons []promotion.Coupon
+	for rows.Next() {
+		var coupon promotion.Coupon
+		if err := scanCoupon(rows, &coupon); err != nil {
+			return nil, 0, err
+		}
+		coupons = append(coupons, coupon)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return coupons, total, nil
+}
+
+// FindEligibleProductIds matches products listed on the coupon directly and
+// products in any of its categories or their subcategories.
+func (c *couponRepositoryImpl) FindEligibleProductIds(ctx context.Context, couponId string, productIds []string) ([]string, error) {
+	db := c.tx.GetTx(ctx)
+	query := "WITH RECURSIVE scope AS (" +
+		"SELECT category_id AS id FROM coupon_categories WHERE coupon_id = $1 " +
+		"UNION " +
+		"SELECT child.id FROM categories child JOIN scope ON child.parent_id = scope.id" +
+		") SELECT p.id FROM products p WHERE p.id = ANY($2::bigint[]) AND (" +
+		"p.category_id IN (SELECT id FROM scope) " +
+		"OR EXISTS (SELECT 1 FROM coupon_products cp WHERE cp.coupon_id = $1 AND cp.product_id = p.id))"
+	rows, err := db.Query(ctx, query, couponId, productIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var eligible []string
+	for rows.Next() {
+		var productId string
+		if err := rows.Scan(&productId); err != nil {
+			return nil, err
+		}
+		eligible = append(eligible, productId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return eligible, nil
+}
+
+func (c *couponRepositoryImpl) Deactivate(ctx context.Context, id string) error {
+	db := c.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "UPDATE coupons SET active = FALSE, updated_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrCouponNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/idempotency"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type idempotencyRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewIdempotency(tx *helper.Transaction) idempotency.Repository {
+	return &idempotencyRepositoryImpl{tx: tx}
+}
+
+func (i *idempotencyRepositoryImpl) Reserve(ctx context.Context, record idempotency.Record) (idempotency.Record, bool, error) {
+	db := i.tx.GetTx(ctx)
+
+	// An expired record is taken over as if the key had never been used. An
+	// unfinished one whose lease ran out is taken over only by the same
+	// request, so the key still does not fit any other.
+	query := "INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at, locked_until) VALUES ($1, $2, $3, $4, $5) " +
+		"ON CONFLICT (user_id, key) DO UPDATE SET " +
+		"request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL, expires_at = EXCLUDED.expires_at, locked_until = EXCLUDED.locked_until, created_at = NOW() " +
+		"WHERE idempotency_keys.expires_at <= NOW() " +
+		"OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= NOW() AND idempotency_keys.request_hash = EXCLUDED.request_hash) " +
+		"RETURNING user_id"
+	err := db.QueryRow(
+		ctx,
+		query,
+		record.UserID,
+		record.Key,
+		record.Fingerprint,
+		record.ExpiresAt,
+		record.LockedUntil,
+	).Scan(&record.UserID)
+	if err == nil {
+		return record, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return idempotency.Record{}, false, err
+	}
+
+	query = "SELECT user_id, key, request_hash, COALESCE(status_code, 0), response_body, expires_at, COALESCE(locked_until, expires_at) FROM idempotency_keys WHERE user_id = $1 AND key = $2"
+	var stored idempotency.Record
+	if err := db.QueryRow(
+		ctx,
+		query,
+		record.UserID,
+		record.Key,
+	).Scan(
+		&stored.UserID,
+		&stored.Key,
+		&stored.Fingerprint,
+		&stored.StatusCode,
+		&stored.Body,
+		&stored.ExpiresAt,
+		&stored.LockedUntil,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Released between the two statements; the owner is finishing up.
+			return idempotency.Record{}, false, helper.ErrIdempotencyKeyInProgress
+		}
+		return idempotency.Record{}, false, err
+	}
+
+	return stored, false, nil
+}
+
+func (i *idempotencyRepositoryImpl) Complete(ctx context.Context, userId int, key string, statusCode int, body []byte) error {
+	db := i.tx.GetTx(ctx)
+	query := "UPDATE idempotency_keys SET status_code = $1, response_body = $2, locked_until = NULL WHERE user_id = $3 AND key = $4"
+	_, err := db.Exec(ctx, query, statusCode, body, userId, key)
+	return err
+}
+
+func (i *idempotencyRepositoryImpl) Release(ctx context.Context, userId int, key string) error {
+	db := i.tx.GetTx(ctx)
+	query := "DELETE FROM idempotency_keys WHERE user_id = $1 AND key = $2 AND status_code IS NULL"
+	_, err := db.Exec(ctx, query, userId, key)
+	return err
+}
+
+func (i *idempotencyRepositoryImpl) DeleteExpired(ctx context.Context) (int64, error) {
+	db := i.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,140 @@
+//go:build integration
+
+// Run with a disposable database; pending migrations are applied to it:
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags integration ./internal/repository/
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"mini-ecommerce/internal/config"
+	"mini-ecommerce/internal/database"
+	"mini-ecommerce/internal/database/migrations"
+	"mini-ecommerce/internal/domain/idempotency"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/repository"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestIdempotencyReserve checks the claims Reserve grants against the key's
+// stored state, in particular the takeover of an unfinished key whose lease
+// ran out.
+func TestIdempotencyReserve(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, config.Database{URL: url, MaxConns: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	userData := user.Data{
+		Name:     "Idempotency",
+		Email:    fmt.Sprintf("idempotency-%d@example.invalid", time.Now().UnixNano()),
+		Password: "-",
+	}
+	if err := repository.NewUser(db).Create(ctx, &userData); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(context.Background(), "DELETE FROM users WHERE id = $1", userData.ID); err != nil {
+			t.Errorf("clean up: %v", err)
+		}
+	})
+
+	idempotencyRepository := repository.NewIdempotency(helper.NewTransaction(db))
+	now := time.Now()
+	record := func(key string, fingerprint string, expiresAt time.Time, lockedUntil time.Time) idempotency.Record {
+		return idempotency.Record{
+			UserID:      userData.ID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   expiresAt,
+			LockedUntil: lockedUntil,
+		}
+	}
+	reserve := func(want bool, r idempotency.Record) idempotency.Record {
+		t.Helper()
+		stored, reserved, err := idempotencyRepository.Reserve(ctx, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reserved != want {
+			t.Fatalf("reserve %s with %s: reserved = %v, want %v", r.Key, r.Fingerprint, reserved, want)
+		}
+		return stored
+	}
+
+	// request_hash holds 64 characters, like a hex SHA-256.
+	hash := func(c string) string { return strings.Repeat(c, 64) }
+	hour := now.Add(time.Hour)
+
+	t.Run("in progress", func(t *testing.T) {
+		reserve(true, record("progress", hash("a"), hour, hour))
+		stored := reserve(false, record("progress", hash("a"), hour, hour))
+		if stored.Completed() {
+			t.Error("unfinished key reported as completed")
+		}
+	})
+
+	t.Run("different request", func(t *testing.T) {
+		reserve(true, record("mismatch", hash("a"), hour, hour))
+		stored := reserve(false, record("mismatch", hash("b"), hour, hour))
+		if stored.Fingerprint != hash("a") {
+			t.Errorf("stored fingerprint %s, want the first request's", stored.Fingerprint)
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		reserve(true, record("replay", hash("a"), hour, hour))
+		if err := idempotencyRepository.Complete(ctx, userData.ID, "replay", 201, []byte(`{"ok":true}`)); err != nil {
+			t.Fatal(err)
+		}
+		stored := reserve(false, record("replay", hash("a"), hour, hour))
+		if stored.StatusCode != 201 || string(stored.Body) != `{"ok":true}` {
+			t.Errorf("stored %d %s, want the completed response", stored.StatusCode, stored.Body)
+		}
+	})
+
+	t.Run("lease expired", func(t *testing.T) {
+		past := now.Add(-time.Second)
+		reserve(true, record("lease", hash("a"), hour, past))
+		reserve(false, record("lease", hash("b"), hour, hour))
+		reserve(true, record("lease", hash("a"), hour, hour))
+		reserve(false, record("lease", hash("a"), hour, hour))
+	})
+
+	t.Run("completed keeps its lease", func(t *testing.T) {
+		past := now.Add(-time.Second)
+		reserve(true, record("completed", hash("a"), hour, past))
+		if err := idempotencyRepository.Complete(ctx, userData.ID, "completed", 200, []byte(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+		if stored := reserve(false, record("completed", hash("a"), hour, hour)); !stored.Completed() {
+			t.Error("completed key was taken over")
+		}
+	})
+
+	t.Run("key expired", func(t *testing.T) {
+		reserve(true, record("expired", hash("a"), now.Add(-time.Second), hour))
+		reserve(true, record("expired", hash("b"), hour, hour))
+	})
+}
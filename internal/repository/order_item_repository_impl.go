@@ -16,7 +16,7 @@ func NewOrderItem(tx *helper.Transaction) order.ItemRepository {
 
 func (o *orderItemRepositoryImpl) CreateItems(ctx context.Context, items []order.Item) error {
 	db := o.tx.GetTx(ctx)
-	query := "INSERT INTO order_items (order_id, product_id, price, quantity) VALUES ($1, $2, $3, $4) RETURNING id"
+	query := "INSERT INTO order_items (order_id, product_id, variant_id, price, currency, quantity) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
 
 	for i := range items {
 		if err := db.QueryRow(
@@ -24,7 +24,9 @@ func (o *orderItemRepositoryImpl) CreateItems(ctx context.Context, items []order
 			query,
 			items[i].OrderID,
 			items[i].ProductID,
-			items[i].Price,
+			items[i].VariantID,
+			items[i].Price.Amount,
+			items[i].Price.Currency,
 			items[i].Quantity,
 		).Scan(&items[i].ID); err != nil {
 			return err
@@ -36,7 +38,7 @@ func (o *orderItemRepositoryImpl) CreateItems(ctx context.Context, items []order
 
 func (o *orderItemRepositoryImpl) FindItems(ctx context.Context, orderId int) ([]order.Item, error) {
 	db := o.tx.GetTx(ctx)
-	query := "SELECT id, order_id, product_id, price, quantity FROM order_items WHERE order_id = $1"
+	query := "SELECT oi.id, oi.order_id, oi.product_id, oi.variant_id, v.sku, oi.price, oi.currency, oi.quantity FROM order_items oi JOIN product_variants v ON v.id = oi.variant_id WHERE oi.order_id = $1 ORDER BY oi.id"
 	rows, err := db.Query(ctx, query, orderId)
 	if err != nil {
 		return nil, err
@@ -50,7 +52,10 @@ func (o *orderItemRepositoryImpl) FindItems(ctx context.Context, orderId int) ([
 			&orderItem.ID,
 			&orderItem.OrderID,
 			&orderItem.ProductID,
-			&orderItem.Price,
+			&orderItem.VariantID,
+			&orderItem.SKU,
+			&orderItem.Price.Amount,
+			&orderItem.Price.Currency,
 			&orderItem.Quantity,
 		); err != nil {
 			return nil, err
@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"mini-ecommerce/internal/domain/address"
 	"mini-ecommerce/internal/domain/order"
 	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
 
 	"github.com/jackc/pgx/v5"
 )
@@ -19,30 +21,99 @@ func NewOrder(tx *helper.Transaction) order.Repository {
 
 func (o *orderRepositoryImpl) Create(ctx context.Context, data *order.Data) error {
 	db := o.tx.GetTx(ctx)
-	query := "INSET INTO orders (user_id, total_price, status) VALUES ($1, $2, $3) RETURNING id"
+	query := "INSERT INTO orders (user_id, subtotal, discount, shipping, tax, total_price, currency, coupon_code, free_shipping, region, shipping_address, status) " +
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, NULLIF($10, ''), $11, $12) RETURNING id"
 	return db.QueryRow(
 		ctx,
 		query,
 		data.UserID,
-		data.TotalPrice,
+		data.Subtotal.Amount,
+		data.Discount.Amount,
+		data.Shipping.Amount,
+		data.Tax.Amount,
+		data.TotalPrice.Amount,
+		data.TotalPrice.Currency,
+		data.CouponCode,
+		data.FreeShipping,
+		data.Region,
+		newAddressSnapshot(data.ShippingAddress),
 		data.Status,
 	).Scan(&data.ID)
 }
 
-func (o *orderRepositoryImpl) FindById(ctx context.Context, id int) (order.Data, error) {
-	db := o.tx.GetTx(ctx)
-	query := "SELECT id, user_id, total_price, status FROM orders WHERE id = $1"
-	var orderData order.Data
-	if err := db.QueryRow(
-		ctx,
-		query,
-		id,
-	).Scan(
+// orderSelect reads the currency once per amount, as they always match.
+const orderSelect = "SELECT id, user_id, subtotal, currency, discount, currency, shipping, currency, tax, currency, total_price, currency, refunded, currency, " +
+	"COALESCE(coupon_code, ''), free_shipping, COALESCE(region, ''), shipping_address, status FROM orders"
+
+// addressSnapshot is the JSON form of order.Data.ShippingAddress.
+type addressSnapshot struct {
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Region     string `json:"region"`
+	Country    string `json:"country"`
+}
+
+func newAddressSnapshot(shippingAddress *address.Address) *addressSnapshot {
+	if shippingAddress == nil {
+		return nil
+	}
+
+	snapshot := addressSnapshot(*shippingAddress)
+	return &snapshot
+}
+
+func (s *addressSnapshot) address() *address.Address {
+	if s == nil {
+		return nil
+	}
+
+	shippingAddress := address.Address(*s)
+	return &shippingAddress
+}
+
+func scanOrder(row pgx.Row, orderData *order.Data) error {
+	var snapshot *addressSnapshot
+	err := row.Scan(
 		&orderData.ID,
 		&orderData.UserID,
-		&orderData.TotalPrice,
+		&orderData.Subtotal.Amount,
+		&orderData.Subtotal.Currency,
+		&orderData.Discount.Amount,
+		&orderData.Discount.Currency,
+		&orderData.Shipping.Amount,
+		&orderData.Shipping.Currency,
+		&orderData.Tax.Amount,
+		&orderData.Tax.Currency,
+		&orderData.TotalPrice.Amount,
+		&orderData.TotalPrice.Currency,
+		&orderData.Refunded.Amount,
+		&orderData.Refunded.Currency,
+		&orderData.CouponCode,
+		&orderData.FreeShipping,
+		&orderData.Region,
+		&snapshot,
 		&orderData.Status,
-	); err != nil {
+	)
+	orderData.ShippingAddress = snapshot.address()
+	return err
+}
+
+func (o *orderRepositoryImpl) FindById(ctx context.Context, id int) (order.Data, error) {
+	return o.find(ctx, orderSelect+" WHERE id = $1", id)
+}
+
+func (o *orderRepositoryImpl) FindByIdForUpdate(ctx context.Context, id int) (order.Data, error) {
+	return o.find(ctx, orderSelect+" WHERE id = $1 FOR UPDATE", id)
+}
+
+func (o *orderRepositoryImpl) find(ctx context.Context, query string, id int) (order.Data, error) {
+	db := o.tx.GetTx(ctx)
+	var orderData order.Data
+	if err := scanOrder(db.QueryRow(ctx, query, id), &orderData); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return order.Data{}, helper.ErrOrderNotFound
 		}
@@ -52,39 +123,48 @@ func (o *orderRepositoryImpl) FindById(ctx context.Context, id int) (order.Data,
 	return orderData, nil
 }
 
-func (o *orderRepositoryImpl) FindByUserId(ctx context.Context, userId int) ([]order.Data, error) {
+func (o *orderRepositoryImpl) FindByUserId(ctx context.Context, userId int, page helper.PageRequest) ([]order.Data, int, error) {
 	db := o.tx.GetTx(ctx)
-	query := "SELECT id, user_id, total_price, status FROM orders WHERE user_id = $1 ORDER BY created_at DESC"
-	rows, err := db.Query(ctx, query, userId)
+
+	var total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM orders WHERE user_id = $1", userId).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := orderSelect + " WHERE user_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3"
+	rows, err := db.Query(ctx, query, userId, page.Limit, page.Offset())
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	var orders []order.Data
 	for rows.Next() {
 		var orderData order.Data
-		if err := rows.Scan(
-			&orderData.ID,
-			&orderData.UserID,
-			&orderData.TotalPrice,
-			&orderData.Status,
-		); err != nil {
-			return nil, err
+		if err := scanOrder(rows, &orderData); err != nil {
+			return nil, 0, err
 		}
 		orders = append(orders, orderData)
 	}
 
-	return orders, rows.Err()
+	return orders, total, rows.Err()
 }
 
 func (o *orderRepositoryImpl) Update(ctx context.Context, update *order.Update) error {
+	var totalAmount *int64
+	var totalCurrency *string
+	if update.TotalPrice != nil {
+		totalAmount = &update.TotalPrice.Amount
+		totalCurrency = &update.TotalPrice.Currency
+	}
+
 	db := o.tx.GetTx(ctx)
-	query := "UPDATE orders SET total_price = COALESCE($1, total_price), status = COALESCE($2, status), updated_at = NOW() WHERE id = $3"
+	query := "UPDATE orders SET total_price = COALESCE($1, total_price), currency = COALESCE($2, currency), status = COALESCE($3, status), updated_at = NOW() WHERE id = $4"
 	cmd, err := db.Exec(
 		ctx,
 		query,
-		update.TotalPrice,
+		totalAmount,
+		totalCurrency,
 		update.Status,
 		update.ID,
 	)
@@ -115,6 +195,28 @@ func (o *orderRepositoryImpl) UpdateStatus(ctx context.Context, id int, status o
 	return nil
 }
 
+func (o *orderRepositoryImpl) HasPendingPayment(ctx context.Context, id int) (bool, error) {
+	db := o.tx.GetTx(ctx)
+	var pending bool
+	err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM payments WHERE order_id = $1 AND status = 'pending')", id).Scan(&pending)
+	return pending, err
+}
+
+func (o *orderRepositoryImpl) AddRefunded(ctx context.Context, id int, amount money.Money) error {
+	db := o.tx.GetTx(ctx)
+	query := "UPDATE orders SET refunded = refunded + $1, updated_at = NOW() WHERE id = $2 AND currency = $3"
+	cmd, err := db.Exec(ctx, query, amount.Amount, id, amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrOrderNotFound
+	}
+
+	return nil
+}
+
 func (o *orderRepositoryImpl) Delete(ctx context.Context, id int) error {
 	db := o.tx.GetTx(ctx)
 	query := "DELETE FROM orders WHERE id = $1"
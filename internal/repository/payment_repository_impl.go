@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/helper"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type paymentRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewPayment(tx *helper.Transaction) payment.Repository {
+	return &paymentRepositoryImpl{tx: tx}
+}
+
+func (p *paymentRepositoryImpl) Create(ctx context.Context, data *payment.Data) error {
+	db := p.tx.GetTx(ctx)
+	query := "INSERT INTO payments (order_id, payment_method, status, amount, currency) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	return db.QueryRow(
+		ctx,
+		query,
+		data.OrderID,
+		data.Method,
+		data.Status,
+		data.Amount.Amount,
+		data.Amount.Currency,
+	).Scan(&data.ID)
+}
+
+func (p *paymentRepositoryImpl) FindById(ctx context.Context, id int) (payment.Data, error) {
+	db := p.tx.GetTx(ctx)
+	query := "SELECT id, order_id, payment_method, status, amount, currency, COALESCE(reference, ''), paid_at FROM payments WHERE id = $1"
+	var paymentData payment.Data
+	if err := db.QueryRow(
+		ctx,
+		query,
+		id,
+	).Scan(
+		&paymentData.ID,
+		&paymentData.OrderID,
+		&paymentData.Method,
+		&paymentData.Status,
+		&paymentData.Amount.Amount,
+		&paymentData.Amount.Currency,
+		&paymentData.Reference,
+		&paymentData.PaidAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return payment.Data{}, helper.ErrPaymentNotFound
+		}
+		return payment.Data{}, err
+	}
+
+	return paymentData, nil
+}
+
+func (p *paymentRepositoryImpl) FindByOrderId(ctx context.Context, orderId int) ([]payment.Data, error) {
+	query := "SELECT id, order_id, payment_method, status, amount, currency, COALESCE(reference, ''), paid_at FROM payments WHERE order_id = $1 ORDER BY id"
+	return p.list(ctx, query, orderId)
+}
+
+func (p *paymentRepositoryImpl) FindPendingBefore(ctx context.Context, before time.Time) ([]payment.Data, error) {
+	query := "SELECT id, order_id, payment_method, status, amount, currency, COALESCE(reference, ''), paid_at FROM payments WHERE status = $1 AND created_at < $2 ORDER BY id"
+	return p.list(ctx, query, payment.StatusPending, before)
+}
+
+func (p *paymentRepositoryImpl) list(ctx context.Context, query string, args ...any) ([]payment.Data, error) {
+	db := p.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []payment.Data
+	for rows.Next() {
+		var paymentData payment.Data
+		if err := rows.Scan(
+			&paymentData.ID,
+			&paymentData.OrderID,
+			&paymentData.Method,
+			&paymentData.Status,
+			&paymentData.Amount.Amount,
+			&paymentData.Amount.Currency,
+			&paymentData.Reference,
+			&paymentData.PaidAt,
+		); err != nil {
+			return nil, err
+		}
+		payments = append(payments, paymentData)
+	}
+
+	return payments, rows.Err()
+}
+
+func (p *paymentRepositoryImpl) Update(ctx context.Context, update *payment.Update) error {
+	db := p.tx.GetTx(ctx)
+	query := "UPDATE payments SET status = $1, reference = $2, paid_at = $3, updated_at = NOW() WHERE id = $4"
+	cmd, err := db.Exec(
+		ctx,
+		query,
+		update.Status,
+		update.Reference,
+		update.PaidAt,
+		update.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrPaymentNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type productImageRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewProductImage(tx *helper.Transaction) product.ImageRepository {
+	return &productImageRepositoryImpl{tx: tx}
+}
+
+const imageSelect = "SELECT id, product_id, key, thumbnail_key, content_type, width, height, position, is_primary FROM product_images"
+
+func scanImage(row pgx.Row, image *product.Image) error {
+	return row.Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.Key,
+		&image.ThumbnailKey,
+		&image.ContentType,
+		&image.Width,
+		&image.Height,
+		&image.Position,
+		&image.Primary,
+	)
+}
+
+// Create locks the product row so concurrent uploads to the same product
+// cannot both become primary or take the same position. It must run inside
+// ExecTx for the lock to cover the insert.
+func (i *productImageRepositoryImpl) Create(ctx context.Context, image *product.Image) error {
+	db := i.tx.GetTx(ctx)
+
+	var locked int
+	if err := db.QueryRow(ctx, "SELECT 1 FROM products WHERE id = $1 FOR UPDATE", image.ProductID).Scan(&locked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return helper.ErrProductNotFound
+		}
+		return err
+	}
+
+	query := "INSERT INTO product_images (product_id, key, thumbnail_key, content_type, width, height, position, is_primary) " +
+		"SELECT $1, $2, $3, $4, $5, $6, COALESCE(MAX(position) + 1, 0), COUNT(*) = 0 FROM product_images WHERE product_id = $1 " +
+		"RETURNING id, position, is_primary"
+	return db.QueryRow(
+		ctx,
+		query,
+		image.ProductID,
+		image.Key,
+		image.ThumbnailKey,
+		image.ContentType,
+		image.Width,
+		image.Height,
+	).Scan(&image.ID, &image.Position, &image.Primary)
+}
+
+func (i *productImageRepositoryImpl) FindById(ctx context.Context, id string) (product.Image, error) {
+	db := i.tx.GetTx(ctx)
+	var image product.Image
+	if err := scanImage(db.QueryRow(ctx, imageSelect+" WHERE id = $1", id), &image); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return product.Image{}, helper.ErrImageNotFound
+		}
+		return product.Image{}, err
+	}
+
+	return image, nil
+}
+
+func (i *productImageRepositoryImpl) FindByProductId(ctx context.Context, productId string) ([]product.Image, error) {
+	db := i.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, imageSelect+" WHERE product_id = $1 ORDER BY position, id", productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []product.Image
+	for rows.Next() {
+		var image product.Image
+		if err := scanImage(rows, &image); err != nil {
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// SetPrimary clears the old primary image before marking the new one, as the
+// unique index on primary images is checked row by row.
+func (i *productImageRepositoryImpl) SetPrimary(ctx context.Context, productId string, id string) error {
+	db := i.tx.GetTx(ctx)
+	if _, err := db.Exec(ctx, "UPDATE product_images SET is_primary = FALSE WHERE product_id = $1 AND is_primary AND id <> $2", productId, id); err != nil {
+		return err
+	}
+
+	cmd, err := db.Exec(ctx, "UPDATE product_images SET is_primary = TRUE WHERE product_id = $1 AND id = $2", productId, id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrImageNotFound
+	}
+
+	return nil
+}
+
+func (i *productImageRepositoryImpl) Reorder(ctx context.Context, productId string, ids []string) error {
+	db := i.tx.GetTx(ctx)
+	query := "UPDATE product_images i SET position = o.position - 1 FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position) WHERE i.id = o.id AND i.product_id = $1"
+	cmd, err := db.Exec(ctx, query, productId, ids)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() != int64(len(ids)) {
+		return helper.ErrImageNotFound
+	}
+
+	return nil
+}
+
+func (i *productImageRepositoryImpl) Delete(ctx context.Context, id string) error {
+	db := i.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "DELETE FROM product_images WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrImageNotFound
+	}
+
+	return nil
+}
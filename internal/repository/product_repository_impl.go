@@ -3,33 +3,37 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"html"
 	"mini-ecommerce/internal/domain/product"
 	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type productRepositoryImpl struct {
-	db *pgxpool.Pool
 	tx *helper.Transaction
 }
 
-func NewProduct(db *pgxpool.Pool, tx *helper.Transaction) product.Repository {
-	return &productRepositoryImpl{db: db, tx: tx}
+func NewProduct(tx *helper.Transaction) product.Repository {
+	return &productRepositoryImpl{tx: tx}
 }
 
 func (p *productRepositoryImpl) Create(ctx context.Context, data *product.Data) error {
-	query := "INSERT INTO products (category_id, name, description, price, stock) VALUES ($1, $2, $3, $4, $5) RETURNING id"
-	err := p.db.QueryRow(
+	db := p.tx.GetTx(ctx)
+	query := "INSERT INTO products (category_id, name, description, price, currency, weight_grams) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+	err := db.QueryRow(
 		ctx,
 		query,
 		data.CategoryID,
 		data.Name,
 		data.Description,
-		data.Price,
-		data.Stock,
+		data.Price.Amount,
+		data.Price.Currency,
+		data.WeightGrams,
 	).Scan(&data.ID)
 
 	if err != nil {
@@ -44,9 +48,11 @@ func (p *productRepositoryImpl) Create(ctx context.Context, data *product.Data)
 }
 
 func (p *productRepositoryImpl) Find(ctx context.Context, id string) (product.Data, error) {
-	query := "SELECT id, category_id, name, description, price, stock FROM products WHERE id = $1"
+	db := p.tx.GetTx(ctx)
+	query := "SELECT p.id, p.category_id, p.name, p.description, p.price, p.currency, p.weight_grams, " + productStockColumn + ", " + primaryImageColumns + " FROM products p" + primaryImageJoin + " WHERE p.id = $1"
 	var productData product.Data
-	err := p.db.QueryRow(
+	var image primaryImage
+	err := db.QueryRow(
 		ctx,
 		query,
 		id,
@@ -55,8 +61,13 @@ func (p *productRepositoryImpl) Find(ctx context.Context, id string) (product.Da
 		&productData.CategoryID,
 		&productData.Name,
 		&productData.Description,
-		&productData.Price,
+		&productData.Price.Amount,
+		&productData.Price.Currency,
+		&productData.WeightGrams,
 		&productData.Stock,
+		&image.id,
+		&image.key,
+		&image.thumbnailKey,
 	)
 
 	if err != nil {
@@ -67,14 +78,144 @@ func (p *productRepositoryImpl) Find(ctx context.Context, id string) (product.Da
 		return product.Data{}, err
 	}
 
+	productData.PrimaryImage = image.image(productData.ID)
 	return productData, nil
 }
 
-func (p *productRepositoryImpl) FindAll(ctx context.Context) ([]product.Data, error) {
-	query := "SELECT id, category_id, name, description, price, stock FROM products"
-	rows, err := p.db.Query(ctx, query)
+// productStockColumn totals the stock of every variant of the product p.
+const productStockColumn = "(SELECT COALESCE(SUM(v.stock), 0) FROM product_variants v WHERE v.product_id = p.id)"
+
+// variantPriceColumn is the effective price of variant v of product p.
+const variantPriceColumn = "COALESCE(v.price, p.price)"
+
+// productLowestPriceColumn is the lowest effective price among the variants of
+// product p, the price it is sorted by.
+const productLowestPriceColumn = "(SELECT MIN(" + variantPriceColumn + ") FROM product_variants v WHERE v.product_id = p.id)"
+
+// primaryImageJoin attaches the primary image of product p, if any, as pi.
+// The partial unique index on primary images keeps it to one row.
+const primaryImageJoin = " LEFT JOIN product_images pi ON pi.product_id = p.id AND pi.is_primary"
+
+const primaryImageColumns = "pi.id, pi.key, pi.thumbnail_key"
+
+// primaryImage receives primaryImageColumns, which are NULL for a product
+// without images.
+type primaryImage struct {
+	id           *string
+	key          *string
+	thumbnailKey *string
+}
+
+func (i primaryImage) image(productId string) *product.Image {
+	if i.id == nil {
+		return nil
+	}
+
+	return &product.Image{
+		ID:           *i.id,
+		ProductID:    productId,
+		Key:          *i.key,
+		ThumbnailKey: *i.thumbnailKey,
+		Primary:      true,
+	}
+}
+
+var productSortColumns = map[product.SortField]string{
+	product.SortByCreatedAt: "p.created_at",
+	product.SortByPrice:     productLowestPriceColumn,
+	product.SortByName:      "p.name",
+}
+
+func (p *productRepositoryImpl) Lock(ctx context.Context, id string) error {
+	db := p.tx.GetTx(ctx)
+	var locked string
+	if err := db.QueryRow(ctx, "SELECT id FROM products WHERE id = $1 FOR UPDATE", id).Scan(&locked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return helper.ErrProductNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *productRepositoryImpl) FindAll(ctx context.Context, filter product.Filter) ([]product.Data, int, error) {
+	db := p.tx.GetTx(ctx)
+	var conditions []string
+	var args []any
+
+	if filter.CategoryIDs != nil {
+		args = append(args, filter.CategoryIDs)
+		conditions = append(conditions, fmt.Sprintf("p.category_id = ANY($%d::bigint[])", len(args)))
+	} else if filter.CategoryID != "" {
+		args = append(args, filter.CategoryID)
+		conditions = append(conditions, fmt.Sprintf("p.category_id = $%d", len(args)))
+	}
+
+	// Both bounds apply to the same variant, at its effective price.
+	var priceConditions []string
+	if filter.MinPrice != nil {
+		args = append(args, *filter.MinPrice)
+		priceConditions = append(priceConditions, fmt.Sprintf("%s >= $%d", variantPriceColumn, len(args)))
+	}
+
+	if filter.MaxPrice != nil {
+		args = append(args, *filter.MaxPrice)
+		priceConditions = append(priceConditions, fmt.Sprintf("%s <= $%d", variantPriceColumn, len(args)))
+	}
+
+	if len(priceConditions) > 0 {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND "+strings.Join(priceConditions, " AND ")+")")
+	}
+
+	if filter.InStock {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = p.id AND v.stock > 0)")
+	}
+
+	if filter.Search != "" {
+		args = append(args, filter.Search)
+		// strpos matches the text literally, where ILIKE would treat % and _
+		// in it as wildcards.
+		conditions = append(conditions, fmt.Sprintf("strpos(lower(p.name), lower($%d)) > 0", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM products p"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	sortColumn, ok := productSortColumns[filter.SortBy]
+	if !ok {
+		sortColumn = productSortColumns[product.SortByCreatedAt]
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	args = append(args, filter.Page.Limit, filter.Page.Offset())
+	query := fmt.Sprintf(
+		"SELECT p.id, p.category_id, p.name, p.description, p.price, p.currency, p.weight_grams, %s, %s FROM products p%s%s ORDER BY %s %s, p.id %s LIMIT $%d OFFSET $%d",
+		productStockColumn,
+		primaryImageColumns,
+		primaryImageJoin,
+		where,
+		sortColumn,
+		direction,
+		direction,
+		len(args)-1,
+		len(args),
+	)
+
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -82,43 +223,145 @@ func (p *productRepositoryImpl) FindAll(ctx context.Context) ([]product.Data, er
 
 	for rows.Next() {
 		var productData product.Data
+		var image primaryImage
 		if err := rows.Scan(
 			&productData.ID,
 			&productData.CategoryID,
 			&productData.Name,
 			&productData.Description,
-			&productData.Price,
+			&productData.Price.Amount,
+			&productData.Price.Currency,
+			&productData.WeightGrams,
 			&productData.Stock,
+			&image.id,
+			&image.key,
+			&image.thumbnailKey,
 		); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
+		productData.PrimaryImage = image.image(productData.ID)
 		products = append(products, productData)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// productSearchMatch pairs every product p with the parsed query: $1 is the
+// prefix tsquery and $2 the raw text used for trigram typo tolerance.
+const productSearchMatch = ", (SELECT to_tsquery('simple', $1) AS query, $2::text AS raw) q WHERE (p.search_vector @@ q.query OR q.raw <% p.name)"
+
+// Highlights are marked with private use characters, stripped from the text
+// beforehand, so the text can be HTML-escaped before they become <mark> tags.
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+func highlightHTML(text string) string {
+	return highlightReplacer.Replace(html.EscapeString(text))
+}
+
+// Search expects a query with at least one term; see product.SearchTerms.
+func (p *productRepositoryImpl) Search(ctx context.Context, text string, page helper.PageRequest) ([]product.SearchResult, int, error) {
+	db := p.tx.GetTx(ctx)
+	terms := product.SearchTerms(text)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	for i := range terms {
+		terms[i] += ":*"
+	}
+	tsQuery := strings.Join(terms, " & ")
+
+	var total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM products p"+productSearchMatch, tsQuery, text).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT p.id, p.category_id, p.name, p.description, p.price, p.currency, p.weight_grams, " + productStockColumn + ", " + primaryImageColumns + ", " +
+		"ts_rank(p.search_vector, q.query) + word_similarity(q.raw, p.name) AS rank, " +
+		"ts_headline('simple', translate(p.name, '" + highlightStart + highlightStop + "', ''), q.query, 'StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", HighlightAll=true'), " +
+		"ts_headline('simple', translate(p.description, '" + highlightStart + highlightStop + "', ''), q.query, 'StartSel=" + highlightStart + ", StopSel=" + highlightStop + ", MinWords=10, MaxWords=25, MaxFragments=2')" +
+		" FROM products p" + primaryImageJoin + productSearchMatch +
+		" ORDER BY rank DESC, p.id LIMIT $3 OFFSET $4"
+
+	rows, err := db.Query(ctx, query, tsQuery, text, page.Limit, page.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var results []product.SearchResult
+	for rows.Next() {
+		var result product.SearchResult
+		var image primaryImage
+		if err := rows.Scan(
+			&result.Data.ID,
+			&result.Data.CategoryID,
+			&result.Data.Name,
+			&result.Data.Description,
+			&result.Data.Price.Amount,
+			&result.Data.Price.Currency,
+			&result.Data.WeightGrams,
+			&result.Data.Stock,
+			&image.id,
+			&image.key,
+			&image.thumbnailKey,
+			&result.Rank,
+			&result.NameHighlight,
+			&result.Snippet,
+		); err != nil {
+			return nil, 0, err
+		}
+		result.Data.PrimaryImage = image.image(result.Data.ID)
+		result.NameHighlight = highlightHTML(result.NameHighlight)
+		result.Snippet = highlightHTML(result.Snippet)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
 	}
 
-	return products, nil
+	return results, total, nil
 }
 
 func (p *productRepositoryImpl) Update(ctx context.Context, update *product.Update) error {
-	query := "UPDATE products SET category_id = COALESCE($1, category_id), name = COALESCE($2, name), description = COALESCE($3, description), price = COALESCE($4, price), stock = COALESCE($5, stock), updated_at = NOW() WHERE id = $6 RETURNING id, category_id, name, description, price, stock"
-	err := p.db.QueryRow(
+	db := p.tx.GetTx(ctx)
+	var priceAmount *int64
+	var priceCurrency *string
+	if update.Price != nil {
+		priceAmount = &update.Price.Amount
+		priceCurrency = &update.Price.Currency
+	}
+
+	query := "UPDATE products p SET category_id = COALESCE($1, category_id), name = COALESCE($2, name), description = COALESCE($3, description), price = COALESCE($4, price), currency = COALESCE($5, currency), weight_grams = COALESCE($6, weight_grams), updated_at = NOW() WHERE p.id = $7 RETURNING p.id, p.category_id, p.name, p.description, p.price, p.currency, p.weight_grams, " + productStockColumn
+	var price money.Money
+	err := db.QueryRow(
 		ctx,
 		query,
 		update.CategoryID,
 		update.Name,
 		update.Description,
-		update.Price,
-		update.Stock,
+		priceAmount,
+		priceCurrency,
+		update.WeightGrams,
 		update.ID,
 	).Scan(
 		&update.ID,
 		&update.CategoryID,
 		&update.Name,
 		&update.Description,
-		&update.Price,
+		&price.Amount,
+		&price.Currency,
+		&update.WeightGrams,
 		&update.Stock,
 	)
 
@@ -129,28 +372,19 @@ func (p *productRepositoryImpl) Update(ctx context.Context, update *product.Upda
 		return err
 	}
 
-	return nil
-}
-
-func (p *productRepositoryImpl) UpdateStock(ctx context.Context, id string, quantity int) error {
-	db := p.tx.GetTx(ctx)
-	query := "UPDATE products SET stock = stock - $1 WHERE id = $2 AND stock >= $1"
-	cmd, err := db.Exec(ctx, query, quantity, id)
-	if err != nil {
-		return err
-	}
-
-	if cmd.RowsAffected() == 0 {
-		return helper.ErrProductInsufficientStock
-	}
-
+	update.Price = &price
 	return nil
 }
 
 func (p *productRepositoryImpl) Delete(ctx context.Context, id string) error {
+	db := p.tx.GetTx(ctx)
 	query := "DELETE FROM products WHERE id = $1"
-	cmd, err := p.db.Exec(ctx, query, id)
+	cmd, err := db.Exec(ctx, query, id)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return helper.ErrProductInUse
+		}
 		return err
 	}
 
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type productVariantRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewProductVariant(tx *helper.Transaction) product.VariantRepository {
+	return &productVariantRepositoryImpl{tx: tx}
+}
+
+// variantSelect resolves the effective price of every variant against its
+// product, so callers never see a variant without a price.
+const variantSelect = "SELECT v.id, v.product_id, v.sku, v.options, COALESCE(v.price, p.price), p.currency, v.price IS NOT NULL, v.stock, p.weight_grams FROM product_variants v JOIN products p ON p.id = v.product_id"
+
+func scanVariant(row pgx.Row, variant *product.Variant) error {
+	return row.Scan(
+		&variant.ID,
+		&variant.ProductID,
+		&variant.SKU,
+		&variant.Options,
+		&variant.Price.Amount,
+		&variant.Price.Currency,
+		&variant.PriceOverride,
+		&variant.Stock,
+		&variant.WeightGrams,
+	)
+}
+
+func (v *productVariantRepositoryImpl) Create(ctx context.Context, variant *product.Variant) error {
+	db := v.tx.GetTx(ctx)
+
+	var price *int64
+	if variant.PriceOverride {
+		price = &variant.Price.Amount
+	}
+
+	query := "INSERT INTO product_variants (product_id, sku, options, price, stock) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	err := db.QueryRow(
+		ctx,
+		query,
+		variant.ProductID,
+		variant.SKU,
+		variant.Options,
+		price,
+		variant.Stock,
+	).Scan(&variant.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return helper.ErrVariantAlreadyExists
+			case "23503":
+				return helper.ErrProductNotFound
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (v *productVariantRepositoryImpl) FindById(ctx context.Context, id string) (product.Variant, error) {
+	db := v.tx.GetTx(ctx)
+	var variant product.Variant
+	if err := scanVariant(db.QueryRow(ctx, variantSelect+" WHERE v.id = $1", id), &variant); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return product.Variant{}, helper.ErrVariantNotFound
+		}
+		return product.Variant{}, err
+	}
+
+	return variant, nil
+}
+
+func (v *productVariantRepositoryImpl) FindByProductId(ctx context.Context, productId string) ([]product.Variant, error) {
+	return v.findAll(ctx, variantSelect+" WHERE v.product_id = $1 ORDER BY v.id", productId)
+}
+
+// FindForUpdate locks the variant rows in ascending id order, so two
+// transactions reserving overlapping variants always queue instead of
+// deadlocking. It must run inside ExecTx for the locks to outlive the
+// statement. The product rows are not locked.
+func (v *productVariantRepositoryImpl) FindForUpdate(ctx context.Context, ids []string) ([]product.Variant, error) {
+	return v.findAll(ctx, variantSelect+" WHERE v.id = ANY($1::bigint[]) ORDER BY v.id FOR UPDATE OF v", ids)
+}
+
+func (v *productVariantRepositoryImpl) findAll(ctx context.Context, query string, args ...any) ([]product.Variant, error) {
+	db := v.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var variants []product.Variant
+	for rows.Next() {
+		var variant product.Variant
+		if err := scanVariant(rows, &variant); err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+func (v *productVariantRepositoryImpl) Update(ctx context.Context, update *product.VariantUpdate) error {
+	db := v.tx.GetTx(ctx)
+
+	// A nil map would be stored as the JSON null rather than left unchanged.
+	var options any
+	if update.Options != nil {
+		options = update.Options
+	}
+
+	query := "UPDATE product_variants SET sku = COALESCE($1, sku), options = COALESCE($2, options), price = COALESCE($3, price), stock = COALESCE($4, stock), updated_at = NOW() WHERE id = $5"
+	cmd, err := db.Exec(
+		ctx,
+		query,
+		update.SKU,
+		options,
+		update.Price,
+		update.Stock,
+		update.ID,
+	)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return helper.ErrVariantAlreadyExists
+		}
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrVariantNotFound
+	}
+
+	return nil
+}
+
+func (v *productVariantRepositoryImpl) UpdateStock(ctx context.Context, id string, quantity int) error {
+	db := v.tx.GetTx(ctx)
+	query := "UPDATE product_variants SET stock = stock - $1, updated_at = NOW() WHERE id = $2 AND stock >= $1"
+	cmd, err := db.Exec(ctx, query, quantity, id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrProductInsufficientStock
+	}
+
+	return nil
+}
+
+func (v *productVariantRepositoryImpl) IncreaseStock(ctx context.Context, id string, quantity int) error {
+	db := v.tx.GetTx(ctx)
+	query := "UPDATE product_variants SET stock = stock + $1, updated_at = NOW() WHERE id = $2"
+	cmd, err := db.Exec(ctx, query, quantity, id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrVariantNotFound
+	}
+
+	return nil
+}
+
+func (v *productVariantRepositoryImpl) Delete(ctx context.Context, id string) error {
+	db := v.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "DELETE FROM product_variants WHERE id = $1", id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return helper.ErrVariantInUse
+		}
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrVariantNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/auth"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type refreshTokenRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewRefreshToken(tx *helper.Transaction) auth.RefreshTokenRepository {
+	return &refreshTokenRepositoryImpl{tx: tx}
+}
+
+func (r *refreshTokenRepositoryImpl) Create(ctx context.Context, token *auth.RefreshToken) error {
+	db := r.tx.GetTx(ctx)
+	query := "INSERT INTO refresh_tokens (user_id, family_id, token_hash, device_label, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	return db.QueryRow(
+		ctx,
+		query,
+		token.UserID,
+		token.FamilyID,
+		token.TokenHash,
+		token.DeviceLabel,
+		token.ExpiresAt,
+	).Scan(&token.ID)
+}
+
+func (r *refreshTokenRepositoryImpl) FindByHashForUpdate(ctx context.Context, tokenHash string) (auth.RefreshToken, error) {
+	db := r.tx.GetTx(ctx)
+	query := "SELECT id, user_id, family_id, token_hash, device_label, expires_at, revoked_at FROM refresh_tokens WHERE token_hash = $1 FOR UPDATE"
+	var token auth.RefreshToken
+	if err := db.QueryRow(
+		ctx,
+		query,
+		tokenHash,
+	).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.DeviceLabel,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return auth.RefreshToken{}, helper.ErrRefreshTokenInvalid
+		}
+		return auth.RefreshToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *refreshTokenRepositoryImpl) Revoke(ctx context.Context, id int) error {
+	db := r.tx.GetTx(ctx)
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL"
+	_, err := db.Exec(ctx, query, id)
+	return err
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeFamily(ctx context.Context, familyId string) error {
+	db := r.tx.GetTx(ctx)
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL"
+	_, err := db.Exec(ctx, query, familyId)
+	return err
+}
+
+func (r *refreshTokenRepositoryImpl) RevokeAllByUserId(ctx context.Context, userId int) error {
+	db := r.tx.GetTx(ctx)
+	query := "UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL"
+	_, err := db.Exec(ctx, query, userId)
+	return err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/domain/rma"
+	"mini-ecommerce/internal/helper"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type refundRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewRefund(tx *helper.Transaction) rma.RefundRepository {
+	return &refundRepositoryImpl{tx: tx}
+}
+
+func (r *refundRepositoryImpl) Create(ctx context.Context, refund *rma.Refund) error {
+	db := r.tx.GetTx(ctx)
+	query := "INSERT INTO refunds (return_id, payment_id, amount, currency, status) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	return db.QueryRow(
+		ctx,
+		query,
+		refund.ReturnID,
+		refund.PaymentID,
+		refund.Amount.Amount,
+		refund.Amount.Currency,
+		refund.Status,
+	).Scan(&refund.ID)
+}
+
+func scanRefund(row pgx.Row, refund *rma.Refund) error {
+	return row.Scan(
+		&refund.ID,
+		&refund.ReturnID,
+		&refund.PaymentID,
+		&refund.Amount.Amount,
+		&refund.Amount.Currency,
+		&refund.Status,
+		&refund.Reference,
+	)
+}
+
+func (r *refundRepositoryImpl) FindById(ctx context.Context, id string) (rma.Refund, error) {
+	db := r.tx.GetTx(ctx)
+	query := "SELECT id, return_id, payment_id, amount, currency, status, COALESCE(reference, '') FROM refunds WHERE id = $1"
+	var refund rma.Refund
+	if err := scanRefund(db.QueryRow(ctx, query, id), &refund); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rma.Refund{}, helper.ErrRefundNotFound
+		}
+		return rma.Refund{}, err
+	}
+
+	return refund, nil
+}
+
+func (r *refundRepositoryImpl) FindPendingBefore(ctx context.Context, before time.Time) ([]rma.Refund, error) {
+	db := r.tx.GetTx(ctx)
+	query := "SELECT id, return_id, payment_id, amount, currency, status, COALESCE(reference, '') FROM refunds WHERE status = $1 AND created_at < $2 ORDER BY id"
+	rows, err := db.Query(ctx, query, payment.StatusPending, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refunds []rma.Refund
+	for rows.Next() {
+		var refund rma.Refund
+		if err := scanRefund(rows, &refund); err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+func (r *refundRepositoryImpl) HasPending(ctx context.Context, returnId string) (bool, error) {
+	db := r.tx.GetTx(ctx)
+	var pending bool
+	err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM refunds WHERE return_id = $1 AND status = 'pending')", returnId).Scan(&pending)
+	return pending, err
+}
+
+func (r *refundRepositoryImpl) Update(ctx context.Context, refund *rma.Refund) error {
+	db := r.tx.GetTx(ctx)
+	_, err := db.Exec(ctx, "UPDATE refunds SET status = $1, reference = NULLIF($2, '') WHERE id = $3", refund.Status, refund.Reference, refund.ID)
+	return err
+}
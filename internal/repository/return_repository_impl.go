@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/rma"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type returnRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewReturn(tx *helper.Transaction) rma.Repository {
+	return &returnRepositoryImpl{tx: tx}
+}
+
+func (r *returnRepositoryImpl) LockOrder(ctx context.Context, orderId int) error {
+	db := r.tx.GetTx(ctx)
+	var id int
+	if err := db.QueryRow(ctx, "SELECT id FROM orders WHERE id = $1 FOR UPDATE", orderId).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return helper.ErrOrderNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Create must run inside ExecTx so a return is never stored without its items.
+func (r *returnRepositoryImpl) Create(ctx context.Context, data *rma.Return) error {
+	db := r.tx.GetTx(ctx)
+	query := "INSERT INTO returns (order_id, user_id, status, reason, currency) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	if err := db.QueryRow(
+		ctx,
+		query,
+		data.OrderID,
+		data.UserID,
+		data.Status,
+		data.Reason,
+		data.Refunded.Currency,
+	).Scan(&data.ID); err != nil {
+		return err
+	}
+
+	orderItemIds := make([]int, 0, len(data.Items))
+	quantities := make([]int, 0, len(data.Items))
+	for _, item := range data.Items {
+		orderItemIds = append(orderItemIds, item.OrderItemID)
+		quantities = append(quantities, item.Quantity)
+	}
+
+	_, err := db.Exec(
+		ctx,
+		"INSERT INTO return_items (return_id, order_item_id, quantity) SELECT $1, i.order_item_id, i.quantity FROM unnest($2::bigint[], $3::int[]) AS i(order_item_id, quantity)",
+		data.ID,
+		orderItemIds,
+		quantities,
+	)
+	return err
+}
+
+// returnSelect reads returns of alias r with their items as two parallel
+// arrays.
+const returnSelect = "SELECT r.id, r.order_id, r.user_id, r.status, r.reason, r.resolution_note, r.restocked, r.refunded, r.currency, " +
+	"ARRAY(SELECT ri.order_item_id FROM return_items ri WHERE ri.return_id = r.id ORDER BY ri.order_item_id), " +
+	"ARRAY(SELECT ri.quantity FROM return_items ri WHERE ri.return_id = r.id ORDER BY ri.order_item_id) " +
+	"FROM returns r"
+
+func scanReturn(row pgx.Row, data *rma.Return) error {
+	var orderItemIds, quantities []int
+	if err := row.Scan(
+		&data.ID,
+		&data.OrderID,
+		&data.UserID,
+		&data.Status,
+		&data.Reason,
+		&data.ResolutionNote,
+		&data.Restocked,
+		&data.Refunded.Amount,
+		&data.Refunded.Currency,
+		&orderItemIds,
+		&quantities,
+	); err != nil {
+		return err
+	}
+
+	data.Items = make([]rma.Item, 0, len(orderItemIds))
+	for i := range orderItemIds {
+		data.Items = append(data.Items, rma.Item{OrderItemID: orderItemIds[i], Quantity: quantities[i]})
+	}
+
+	return nil
+}
+
+func (r *returnRepositoryImpl) FindById(ctx context.Context, id string) (rma.Return, error) {
+	db := r.tx.GetTx(ctx)
+	var data rma.Return
+	if err := scanReturn(db.QueryRow(ctx, returnSelect+" WHERE r.id = $1", id), &data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rma.Return{}, helper.ErrReturnNotFound
+		}
+		return rma.Return{}, err
+	}
+
+	return data, nil
+}
+
+func (r *returnRepositoryImpl) FindByOrderId(ctx context.Context, orderId int) ([]rma.Return, error) {
+	db := r.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, returnSelect+" WHERE r.order_id = $1 ORDER BY r.created_at, r.id", orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []rma.Return
+	for rows.Next() {
+		var data rma.Return
+		if err := scanReturn(rows, &data); err != nil {
+			return nil, err
+		}
+		returns = append(returns, data)
+	}
+
+	return returns, rows.Err()
+}
+
+func (r *returnRepositoryImpl) FindAll(ctx context.Context, page helper.PageRequest) ([]rma.Return, int, error) {
+	db := r.tx.GetTx(ctx)
+
+	var total int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM returns").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(ctx, returnSelect+" ORDER BY r.created_at DESC, r.id DESC LIMIT $1 OFFSET $2", page.Limit, page.Offset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var returns []rma.Return
+	for rows.Next() {
+		var data rma.Return
+		if err := scanReturn(rows, &data); err != nil {
+			return nil, 0, err
+		}
+		returns = append(returns, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return returns, total, nil
+}
+
+func (r *returnRepositoryImpl) ReturnedQuantities(ctx context.Context, orderId int) (map[int]int, error) {
+	db := r.tx.GetTx(ctx)
+	query := "SELECT ri.order_item_id, SUM(ri.quantity) FROM return_items ri JOIN returns r ON r.id = ri.return_id WHERE r.order_id = $1 AND r.status <> 'rejected' GROUP BY ri.order_item_id"
+	rows, err := db.Query(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returned := map[int]int{}
+	for rows.Next() {
+		var orderItemId, quantity int
+		if err := rows.Scan(&orderItemId, &quantity); err != nil {
+			return nil, err
+		}
+		returned[orderItemId] = quantity
+	}
+
+	return returned, rows.Err()
+}
+
+func (r *returnRepositoryImpl) UpdateStatus(ctx context.Context, id string, status rma.Status, note string) error {
+	db := r.tx.GetTx(ctx)
+	query := "UPDATE returns SET status = $1, resolution_note = COALESCE(NULLIF($2, ''), resolution_note), updated_at = NOW() WHERE id = $3"
+	cmd, err := db.Exec(ctx, query, status, note, id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrReturnNotFound
+	}
+
+	return nil
+}
+
+func (r *returnRepositoryImpl) SetRestocked(ctx context.Context, id string) error {
+	db := r.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "UPDATE returns SET restocked = TRUE, updated_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrReturnNotFound
+	}
+
+	return nil
+}
+
+func (r *returnRepositoryImpl) AddRefunded(ctx context.Context, id string, amount money.Money) error {
+	db := r.tx.GetTx(ctx)
+	query := "UPDATE returns SET refunded = refunded + $1, updated_at = NOW() WHERE id = $2 AND currency = $3"
+	cmd, err := db.Exec(ctx, query, amount.Amount, id, amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrReturnNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/shipment"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type shipmentRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewShipment(tx *helper.Transaction) shipment.Repository {
+	return &shipmentRepositoryImpl{tx: tx}
+}
+
+func (s *shipmentRepositoryImpl) LockOrder(ctx context.Context, orderId int) error {
+	db := s.tx.GetTx(ctx)
+	var id int
+	if err := db.QueryRow(ctx, "SELECT id FROM orders WHERE id = $1 FOR UPDATE", orderId).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return helper.ErrOrderNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Create must run inside ExecTx so a shipment is never stored without its
+// items.
+func (s *shipmentRepositoryImpl) Create(ctx context.Context, data *shipment.Shipment) error {
+	db := s.tx.GetTx(ctx)
+	query := "INSERT INTO shipments (order_id, carrier, tracking_number, status, shipped_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	if err := db.QueryRow(
+		ctx,
+		query,
+		data.OrderID,
+		data.Carrier,
+		data.TrackingNumber,
+		data.Status,
+		data.ShippedAt,
+	).Scan(&data.ID); err != nil {
+		return err
+	}
+
+	orderItemIds := make([]int, 0, len(data.Items))
+	quantities := make([]int, 0, len(data.Items))
+	for _, item := range data.Items {
+		orderItemIds = append(orderItemIds, item.OrderItemID)
+		quantities = append(quantities, item.Quantity)
+	}
+
+	_, err := db.Exec(
+		ctx,
+		"INSERT INTO shipment_items (shipment_id, order_item_id, quantity) SELECT $1, i.order_item_id, i.quantity FROM unnest($2::bigint[], $3::int[]) AS i(order_item_id, quantity)",
+		data.ID,
+		orderItemIds,
+		quantities,
+	)
+	return err
+}
+
+const shipmentSelect = "SELECT id, order_id, carrier, tracking_number, status, shipped_at, delivered_at FROM shipments"
+
+func (s *shipmentRepositoryImpl) FindById(ctx context.Context, id string) (shipment.Shipment, error) {
+	shipments, err := s.find(ctx, shipmentSelect+" WHERE id = $1", id)
+	if err != nil {
+		return shipment.Shipment{}, err
+	}
+
+	if len(shipments) == 0 {
+		return shipment.Shipment{}, helper.ErrShipmentNotFound
+	}
+
+	return shipments[0], nil
+}
+
+func (s *shipmentRepositoryImpl) FindByOrderId(ctx context.Context, orderId int) ([]shipment.Shipment, error) {
+	return s.find(ctx, shipmentSelect+" WHERE order_id = $1 ORDER BY shipped_at, id", orderId)
+}
+
+// find runs query for shipments and attaches their items and events.
+func (s *shipmentRepositoryImpl) find(ctx context.Context, query string, args ...any) ([]shipment.Shipment, error) {
+	db := s.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shipments []shipment.Shipment
+	for rows.Next() {
+		var data shipment.Shipment
+		if err := rows.Scan(
+			&data.ID,
+			&data.OrderID,
+			&data.Carrier,
+			&data.TrackingNumber,
+			&data.Status,
+			&data.ShippedAt,
+			&data.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(shipments) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(shipments))
+	indexById := make(map[string]int, len(shipments))
+	for i, data := range shipments {
+		ids = append(ids, data.ID)
+		indexById[data.ID] = i
+	}
+
+	itemRows, err := db.Query(ctx, "SELECT shipment_id::text, order_item_id, quantity FROM shipment_items WHERE shipment_id = ANY($1::bigint[]) ORDER BY order_item_id", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var shipmentId string
+		var item shipment.Item
+		if err := itemRows.Scan(&shipmentId, &item.OrderItemID, &item.Quantity); err != nil {
+			return nil, err
+		}
+		i := indexById[shipmentId]
+		shipments[i].Items = append(shipments[i].Items, item)
+	}
+
+	if err := itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	eventRows, err := db.Query(ctx, "SELECT id, shipment_id::text, status, description, location, occurred_at FROM shipment_events WHERE shipment_id = ANY($1::bigint[]) ORDER BY occurred_at, id", ids)
+	if err != nil {
+		return nil, err
+	}
+	defer eventRows.Close()
+
+	for eventRows.Next() {
+		var event shipment.Event
+		if err := eventRows.Scan(
+			&event.ID,
+			&event.ShipmentID,
+			&event.Status,
+			&event.Description,
+			&event.Location,
+			&event.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		i := indexById[event.ShipmentID]
+		shipments[i].Events = append(shipments[i].Events, event)
+	}
+
+	if err := eventRows.Err(); err != nil {
+		return nil, err
+	}
+
+	return shipments, nil
+}
+
+func (s *shipmentRepositoryImpl) ShippedQuantities(ctx context.Context, orderId int) (map[int]int, error) {
+	db := s.tx.GetTx(ctx)
+	query := "SELECT si.order_item_id, SUM(si.quantity) FROM shipment_items si JOIN shipments s ON s.id = si.shipment_id WHERE s.order_id = $1 GROUP BY si.order_item_id"
+	rows, err := db.Query(ctx, query, orderId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipped := map[int]int{}
+	for rows.Next() {
+		var orderItemId, quantity int
+		if err := rows.Scan(&orderItemId, &quantity); err != nil {
+			return nil, err
+		}
+		shipped[orderItemId] = quantity
+	}
+
+	return shipped, rows.Err()
+}
+
+func (s *shipmentRepositoryImpl) Update(ctx context.Context, update *shipment.Update) error {
+	db := s.tx.GetTx(ctx)
+	query := "UPDATE shipments SET carrier = COALESCE($1, carrier), tracking_number = COALESCE($2, tracking_number), updated_at = NOW() WHERE id = $3"
+	cmd, err := db.Exec(ctx, query, update.Carrier, update.TrackingNumber, update.ID)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrShipmentNotFound
+	}
+
+	return nil
+}
+
+func (s *shipmentRepositoryImpl) CreateEvent(ctx context.Context, event *shipment.Event) error {
+	db := s.tx.GetTx(ctx)
+	query := "INSERT INTO shipment_events (shipment_id, status, description, location, occurred_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	if err := db.QueryRow(
+		ctx,
+		query,
+		event.ShipmentID,
+		event.Status,
+		event.Description,
+		event.Location,
+		event.OccurredAt,
+	).Scan(&event.ID); err != nil {
+		return err
+	}
+
+	query = "UPDATE shipments SET status = $1, delivered_at = CASE WHEN $2 THEN $3 ELSE delivered_at END, updated_at = NOW() WHERE id = $4"
+	_, err := db.Exec(ctx, query, event.Status, event.Status == shipment.StatusDelivered, event.OccurredAt, event.ShipmentID)
+	return err
+}
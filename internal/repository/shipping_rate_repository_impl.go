@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/pricing"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type shippingRateRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewShippingRate(tx *helper.Transaction) pricing.ShippingRateRepository {
+	return &shippingRateRepositoryImpl{tx: tx}
+}
+
+const shippingRateSelect = "SELECT id, COALESCE(region, ''), min_weight_grams, fee, currency FROM shipping_rates"
+
+func scanShippingRate(row pgx.Row, rate *pricing.ShippingRate) error {
+	return row.Scan(
+		&rate.ID,
+		&rate.Region,
+		&rate.MinWeightGrams,
+		&rate.Fee.Amount,
+		&rate.Fee.Currency,
+	)
+}
+
+func (s *shippingRateRepositoryImpl) Create(ctx context.Context, rate *pricing.ShippingRate) error {
+	db := s.tx.GetTx(ctx)
+	query := "INSERT INTO shipping_rates (region, min_weight_grams, fee, currency) VALUES (NULLIF($1, ''), $2, $3, $4) RETURNING id"
+	err := db.QueryRow(
+		ctx,
+		query,
+		rate.Region,
+		rate.MinWeightGrams,
+		rate.Fee.Amount,
+		rate.Fee.Currency,
+	).Scan(&rate.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return helper.ErrShippingRateAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *shippingRateRepositoryImpl) FindAll(ctx context.Context) ([]pricing.ShippingRate, error) {
+	db := s.tx.GetTx(ctx)
+	rows, err := db.Query(ctx, shippingRateSelect+" ORDER BY region NULLS FIRST, currency, min_weight_grams")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []pricing.ShippingRate
+	for rows.Next() {
+		var rate pricing.ShippingRate
+		if err := scanShippingRate(rows, &rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// FindForWeight prefers a rate for region over one for every region, then the
+// highest weight bracket the order reaches.
+func (s *shippingRateRepositoryImpl) FindForWeight(ctx context.Context, region string, currency string, weightGrams int) (pricing.ShippingRate, error) {
+	db := s.tx.GetTx(ctx)
+	query := shippingRateSelect + " WHERE (region = $1 OR region IS NULL) AND currency = $2 AND min_weight_grams <= $3" +
+		" ORDER BY region IS NULL, min_weight_grams DESC LIMIT 1"
+	var rate pricing.ShippingRate
+	if err := scanShippingRate(db.QueryRow(ctx, query, region, currency, weightGrams), &rate); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pricing.ShippingRate{}, helper.ErrShippingRateNotFound
+		}
+		return pricing.ShippingRate{}, err
+	}
+
+	return rate, nil
+}
+
+func (s *shippingRateRepositoryImpl) Delete(ctx context.Context, id string) error {
+	db := s.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "DELETE FROM shipping_rates WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrShippingRateNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/pricing"
+	"mini-ecommerce/internal/helper"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type taxRateRepositoryImpl struct {
+	tx *helper.Transaction
+}
+
+func NewTaxRate(tx *helper.Transaction) pricing.TaxRateRepository {
+	return &taxRateRepositoryImpl{tx: tx}
+}
+
+func (t *taxRateRepositoryImpl) Create(ctx context.Context, rate *pricing.TaxRate) error {
+	db := t.tx.GetTx(ctx)
+	query := "INSERT INTO tax_rates (region, category_id, basis_points) VALUES (NULLIF($1, ''), NULLIF($2, '')::bigint, $3) RETURNING id"
+	err := db.QueryRow(
+		ctx,
+		query,
+		rate.Region,
+		rate.CategoryID,
+		rate.BasisPoints,
+	).Scan(&rate.ID)
+
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return helper.ErrTaxRateAlreadyExists
+			case "23503":
+				return helper.ErrCategoryNotFound
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (t *taxRateRepositoryImpl) FindAll(ctx context.Context) ([]pricing.TaxRate, error) {
+	db := t.tx.GetTx(ctx)
+	query := "SELECT id, COALESCE(region, ''), COALESCE(category_id::text, ''), basis_points FROM tax_rates ORDER BY region NULLS FIRST, category_id NULLS FIRST"
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []pricing.TaxRate
+	for rows.Next() {
+		var rate pricing.TaxRate
+		if err := rows.Scan(
+			&rate.ID,
+			&rate.Region,
+			&rate.CategoryID,
+			&rate.BasisPoints,
+		); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// FindForProducts walks up from each product's category. A rate for region
+// beats one for every region; among those, the rate of the nearest category
+// wins and a rate without a category comes last.
+func (t *taxRateRepositoryImpl) FindForProducts(ctx context.Context, region string, productIds []string) (map[string]int64, error) {
+	db := t.tx.GetTx(ctx)
+	query := "WITH RECURSIVE ancestry AS (" +
+		"SELECT p.id AS product_id, p.category_id, 0 AS depth FROM products p WHERE p.id = ANY($2::bigint[]) " +
+		"UNION ALL " +
+		"SELECT a.product_id, c.parent_id, a.depth + 1 FROM ancestry a JOIN categories c ON c.id = a.category_id WHERE c.parent_id IS NOT NULL" +
+		") SELECT DISTINCT ON (p.id) p.id::text, t.basis_points " +
+		"FROM products p JOIN tax_rates t ON t.region = $1 OR t.region IS NULL " +
+		"LEFT JOIN ancestry a ON a.product_id = p.id AND a.category_id = t.category_id " +
+		"WHERE p.id = ANY($2::bigint[]) AND (t.category_id IS NULL OR a.product_id IS NOT NULL) " +
+		"ORDER BY p.id, t.region IS NULL, t.category_id IS NULL, a.depth"
+	rows, err := db.Query(ctx, query, region, productIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	basisPoints := map[string]int64{}
+	for rows.Next() {
+		var productId string
+		var points int64
+		if err := rows.Scan(&productId, &points); err != nil {
+			return nil, err
+		}
+		basisPoints[productId] = points
+	}
+
+	return basisPoints, rows.Err()
+}
+
+func (t *taxRateRepositoryImpl) Delete(ctx context.Context, id string) error {
+	db := t.tx.GetTx(ctx)
+	cmd, err := db.Exec(ctx, "DELETE FROM tax_rates WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrTaxRateNotFound
+	}
+
+	return nil
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type userRepositoryImpl struct {
@@ -21,13 +20,18 @@ func NewUser(db *pgxpool.Pool) user.Repository {
 }
 
 func (u *userRepositoryImpl) Create(ctx context.Context, data *user.Data) error {
-	query := "INSERT INTO users (name, email, password) VALUES ($1, $2, $3) RETURNING id"
+	if data.Role == "" {
+		data.Role = user.RoleCustomer
+	}
+
+	query := "INSERT INTO users (name, email, password, role) VALUES ($1, $2, $3, $4) RETURNING id"
 	err := u.db.QueryRow(
 		ctx,
 		query,
 		data.Name,
 		data.Email,
 		data.Password,
+		data.Role,
 	).Scan(&data.ID)
 
 	if err != nil {
@@ -41,42 +45,33 @@ func (u *userRepositoryImpl) Create(ctx context.Context, data *user.Data) error
 	return nil
 }
 
-func (u *userRepositoryImpl) FindByEmail(ctx context.Context, login user.Login) (user.Data, string, error) {
-	query := "SELECT id, name, email, password FROM users WHERE email = $1"
+func (u *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (user.Data, error) {
+	query := "SELECT id, name, email, password, role FROM users WHERE email = $1"
 	var userData user.Data
 	err := u.db.QueryRow(
 		ctx,
 		query,
-		login.Email,
+		email,
 	).Scan(
 		&userData.ID,
 		&userData.Name,
 		&userData.Email,
 		&userData.Password,
+		&userData.Role,
 	)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return user.Data{}, "", helper.ErrUserInvalid
+			return user.Data{}, helper.ErrUserNotFound
 		}
-		return user.Data{}, "", err
-	}
-
-	if err := bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(login.Password)); err != nil {
-		return user.Data{}, "", helper.ErrUserInvalid
-	}
-
-	accessToken, err := helper.GenerateAccessToken(userData.ID, userData.Name, userData.Email)
-
-	if err != nil {
-		return user.Data{}, "", err
+		return user.Data{}, err
 	}
 
-	return userData, accessToken, nil
+	return userData, nil
 }
 
 func (u *userRepositoryImpl) FindById(ctx context.Context, id int) (user.Data, error) {
-	query := "SELECT id, name, email, password FROM users WHERE id = $1"
+	query := "SELECT id, name, email, password, role FROM users WHERE id = $1"
 	var userData user.Data
 	err := u.db.QueryRow(
 		ctx,
@@ -87,6 +82,7 @@ func (u *userRepositoryImpl) FindById(ctx context.Context, id int) (user.Data, e
 		&userData.Name,
 		&userData.Email,
 		&userData.Password,
+		&userData.Role,
 	)
 
 	if err != nil {
@@ -142,3 +138,38 @@ func (u *userRepositoryImpl) Delete(ctx context.Context, id int) error {
 
 	return nil
 }
+
+func (u *userRepositoryImpl) CountByRole(ctx context.Context, role user.Role) (int, error) {
+	query := "SELECT COUNT(*) FROM users WHERE role = $1"
+	var count int
+	err := u.db.QueryRow(ctx, query, role).Scan(&count)
+	return count, err
+}
+
+func (u *userRepositoryImpl) UpdateRoleByEmail(ctx context.Context, email string, role user.Role) error {
+	query := "UPDATE users SET role = $1 WHERE email = $2"
+	cmd, err := u.db.Exec(ctx, query, role, email)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (u *userRepositoryImpl) UpdateRole(ctx context.Context, id int, role user.Role) error {
+	query := "UPDATE users SET role = $1 WHERE id = $2"
+	cmd, err := u.db.Exec(ctx, query, role, id)
+	if err != nil {
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		return helper.ErrUserNotFound
+	}
+
+	return nil
+}
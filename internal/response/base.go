@@ -1,8 +1,16 @@
 package response
 
 type BaseResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Data    any    `json:"data"`
-	Error   any    `json:"error"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       any         `json:"data"`
+	Error      any         `json:"error"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Page       int `json:"page"`
+	Limit      int `json:"limit"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
 }
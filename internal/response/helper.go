@@ -1,6 +1,7 @@
 package response
 
 import (
+	"mini-ecommerce/internal/helper"
 	"net/http"
 )
 
@@ -12,6 +13,20 @@ func Success(message string, data any) (int, BaseResponse) {
 	}
 }
 
+func SuccessPaginated(message string, data any, pagination helper.Pagination) (int, BaseResponse) {
+	return http.StatusOK, BaseResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: pagination.TotalItems,
+			TotalPages: pagination.TotalPages,
+		},
+	}
+}
+
 func SuccessNoContent(message string) (int, BaseResponse) {
 	return http.StatusNoContent, BaseResponse{
 		Success: true,
@@ -27,6 +42,14 @@ func Created(message string, data any) (int, BaseResponse) {
 	}
 }
 
+func Accepted(message string, data any) (int, BaseResponse) {
+	return http.StatusAccepted, BaseResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	}
+}
+
 func Error(message string, err any, status int) (int, BaseResponse) {
 	return status, BaseResponse{
 		Success: false,
@@ -0,0 +1,19 @@
+package response
+
+import "mini-ecommerce/internal/money"
+
+// Money is the wire shape of every amount: integer minor units plus the
+// currency, with a display string for clients that do not format it.
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Display  string `json:"display"`
+}
+
+func NewMoney(m money.Money) Money {
+	return Money{
+		Amount:   m.Amount,
+		Currency: m.Currency,
+		Display:  m.String(),
+	}
+}
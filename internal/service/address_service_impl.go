@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/address"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+	"strings"
+)
+
+type addressServiceImpl struct {
+	tx                *helper.Transaction
+	addressRepository address.Repository
+}
+
+func NewAddress(tx *helper.Transaction, addressRepository address.Repository) address.Service {
+	return &addressServiceImpl{tx: tx, addressRepository: addressRepository}
+}
+
+func (a *addressServiceImpl) Create(ctx context.Context, data *address.Data) *helper.AppError {
+	data.Address.Region = normalizeRegion(data.Address.Region)
+	data.Address.Country = strings.ToUpper(data.Address.Country)
+
+	err := a.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := a.addressRepository.LockByUserId(ctx, data.UserID); err != nil {
+			return err
+		}
+
+		if data.Default {
+			if err := a.addressRepository.ClearDefault(ctx, data.UserID); err != nil {
+				return err
+			}
+		} else {
+			_, err := a.addressRepository.FindDefault(ctx, data.UserID)
+			if errors.Is(err, helper.ErrAddressNotFound) {
+				data.Default = true
+			} else if err != nil {
+				return err
+			}
+		}
+
+		return a.addressRepository.Create(ctx, data)
+	})
+
+	return addressAppError(err)
+}
+
+func (a *addressServiceImpl) Get(ctx context.Context, userId int, id string) (address.Data, *helper.AppError) {
+	data, err := a.addressRepository.FindById(ctx, userId, id)
+	if err != nil {
+		return address.Data{}, addressAppError(err)
+	}
+
+	return data, nil
+}
+
+func (a *addressServiceImpl) GetAll(ctx context.Context, userId int) ([]address.Data, *helper.AppError) {
+	addresses, err := a.addressRepository.FindByUserId(ctx, userId)
+	if err != nil {
+		return nil, addressAppError(err)
+	}
+
+	return addresses, nil
+}
+
+func (a *addressServiceImpl) Update(ctx context.Context, update *address.Update) *helper.AppError {
+	if update.Region != nil {
+		region := normalizeRegion(*update.Region)
+		update.Region = &region
+	}
+
+	if update.Country != nil {
+		country := strings.ToUpper(*update.Country)
+		update.Country = &country
+	}
+
+	err := a.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := a.addressRepository.LockByUserId(ctx, update.UserID); err != nil {
+			return err
+		}
+
+		if update.Default != nil && *update.Default {
+			if _, err := a.addressRepository.FindById(ctx, update.UserID, update.ID); err != nil {
+				return err
+			}
+
+			if err := a.addressRepository.ClearDefault(ctx, update.UserID); err != nil {
+				return err
+			}
+		}
+
+		return a.addressRepository.Update(ctx, update)
+	})
+
+	return addressAppError(err)
+}
+
+func (a *addressServiceImpl) Delete(ctx context.Context, userId int, id string) *helper.AppError {
+	err := a.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := a.addressRepository.LockByUserId(ctx, userId); err != nil {
+			return err
+		}
+
+		data, err := a.addressRepository.FindById(ctx, userId, id)
+		if err != nil {
+			return err
+		}
+
+		if err := a.addressRepository.Delete(ctx, userId, id); err != nil {
+			return err
+		}
+
+		if data.Default {
+			return a.addressRepository.PromoteDefault(ctx, userId)
+		}
+
+		return nil
+	})
+
+	return addressAppError(err)
+}
+
+// addressAppError maps the errors of the address book operations to
+// responses.
+func addressAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, helper.ErrAddressNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Address Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrUserNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"User Not Found",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
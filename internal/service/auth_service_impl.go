@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/auth"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type authServiceImpl struct {
+	tx                     *helper.Transaction
+	refreshTokenRepository auth.RefreshTokenRepository
+	userRepository         user.Repository
+}
+
+func NewAuth(tx *helper.Transaction, refreshTokenRepository auth.RefreshTokenRepository, userRepository user.Repository) auth.Service {
+	return &authServiceImpl{tx: tx, refreshTokenRepository: refreshTokenRepository, userRepository: userRepository}
+}
+
+// Login checks the credentials and starts a new session: a fresh access token
+// and the first refresh token of a new family.
+func (a *authServiceImpl) Login(ctx context.Context, login user.Login, deviceLabel string) (user.Data, auth.TokenPair, *helper.AppError) {
+	var userData user.Data
+	var tokenPair auth.TokenPair
+
+	err := a.tx.ExecTx(ctx, func(ctx context.Context) error {
+		var err error
+		userData, err = a.userRepository.FindByEmail(ctx, login.Email)
+		if err != nil {
+			if errors.Is(err, helper.ErrUserNotFound) {
+				return helper.ErrUserInvalid
+			}
+			return err
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(userData.Password), []byte(login.Password)); err != nil {
+			return helper.ErrUserInvalid
+		}
+
+		tokenPair, err = a.issue(ctx, userData, uuid.NewString(), deviceLabel)
+		return err
+	})
+
+	if err != nil {
+		if errors.Is(err, helper.ErrUserInvalid) {
+			return user.Data{}, auth.TokenPair{}, helper.NewAppError(
+				http.StatusUnauthorized,
+				"Invalid Credentials",
+				err,
+			)
+		}
+
+		return user.Data{}, auth.TokenPair{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return userData, tokenPair, nil
+}
+
+// Refresh exchanges a refresh token for a new pair. The presented token is
+// revoked and replaced by one in the same family; presenting a token that was
+// already revoked is treated as theft and revokes the whole family.
+func (a *authServiceImpl) Refresh(ctx context.Context, refreshToken string) (auth.TokenPair, *helper.AppError) {
+	var tokenPair auth.TokenPair
+	var reused bool
+
+	err := a.tx.ExecTx(ctx, func(ctx context.Context) error {
+		current, err := a.refreshTokenRepository.FindByHashForUpdate(ctx, helper.HashRefreshToken(refreshToken))
+		if err != nil {
+			return err
+		}
+
+		if current.IsRevoked() {
+			reused = true
+			return a.refreshTokenRepository.RevokeFamily(ctx, current.FamilyID)
+		}
+
+		if current.IsExpired(time.Now()) {
+			return helper.ErrRefreshTokenInvalid
+		}
+
+		if err := a.refreshTokenRepository.Revoke(ctx, current.ID); err != nil {
+			return err
+		}
+
+		userData, err := a.userRepository.FindById(ctx, current.UserID)
+		if err != nil {
+			return err
+		}
+
+		tokenPair, err = a.issue(ctx, userData, current.FamilyID, current.DeviceLabel)
+		return err
+	})
+
+	if err == nil && reused {
+		err = helper.ErrRefreshTokenReused
+	}
+
+	if err != nil {
+		if errors.Is(err, helper.ErrRefreshTokenInvalid) || errors.Is(err, helper.ErrRefreshTokenReused) || errors.Is(err, helper.ErrUserNotFound) {
+			return auth.TokenPair{}, helper.NewAppError(
+				http.StatusUnauthorized,
+				"Invalid Refresh Token",
+				err,
+			)
+		}
+
+		return auth.TokenPair{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return tokenPair, nil
+}
+
+// Logout revokes the session the refresh token belongs to. Unknown tokens are
+// ignored so logging out twice is harmless.
+func (a *authServiceImpl) Logout(ctx context.Context, refreshToken string) *helper.AppError {
+	err := a.tx.ExecTx(ctx, func(ctx context.Context) error {
+		current, err := a.refreshTokenRepository.FindByHashForUpdate(ctx, helper.HashRefreshToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, helper.ErrRefreshTokenInvalid) {
+				return nil
+			}
+			return err
+		}
+
+		return a.refreshTokenRepository.RevokeFamily(ctx, current.FamilyID)
+	})
+
+	if err != nil {
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return nil
+}
+
+func (a *authServiceImpl) LogoutAll(ctx context.Context, userId int) *helper.AppError {
+	if err := a.refreshTokenRepository.RevokeAllByUserId(ctx, userId); err != nil {
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// issue mints an access token for userData and stores a new refresh token in
+// the given family.
+func (a *authServiceImpl) issue(ctx context.Context, userData user.Data, familyId string, deviceLabel string) (auth.TokenPair, error) {
+	accessToken, err := helper.GenerateAccessToken(userData.ID, userData.Name, userData.Email, string(userData.Role))
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	refreshToken, tokenHash, err := helper.GenerateRefreshToken()
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	token := auth.RefreshToken{
+		UserID:      userData.ID,
+		FamilyID:    familyId,
+		TokenHash:   tokenHash,
+		DeviceLabel: deviceLabel,
+		ExpiresAt:   time.Now().Add(helper.RefreshTokenTTL()),
+	}
+	if err := a.refreshTokenRepository.Create(ctx, &token); err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	return auth.TokenPair{
+		AccessToken:           accessToken,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: token.ExpiresAt,
+	}, nil
+}
@@ -4,18 +4,28 @@ import (
 	"context"
 	"errors"
 	"mini-ecommerce/internal/domain/cart"
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/domain/product"
 	"mini-ecommerce/internal/helper"
 	"net/http"
 )
 
 type cartServiceImpl struct {
-	tx                 *helper.Transaction
-	cartRepository     cart.Repository
-	cartItemRepository cart.ItemRepository
+	tx                       *helper.Transaction
+	cartRepository           cart.Repository
+	cartItemRepository       cart.ItemRepository
+	productVariantRepository product.VariantRepository
+	orderService             order.Service
 }
 
-func NewCart(tx *helper.Transaction, cartRepository cart.Repository, cartItemRepository cart.ItemRepository) cart.Service {
-	return &cartServiceImpl{tx: tx, cartRepository: cartRepository, cartItemRepository: cartItemRepository}
+func NewCart(tx *helper.Transaction, cartRepository cart.Repository, cartItemRepository cart.ItemRepository, productVariantRepository product.VariantRepository, orderService order.Service) cart.Service {
+	return &cartServiceImpl{
+		tx:                       tx,
+		cartRepository:           cartRepository,
+		cartItemRepository:       cartItemRepository,
+		productVariantRepository: productVariantRepository,
+		orderService:             orderService,
+	}
 }
 
 func (c *cartServiceImpl) GetItems(ctx context.Context, userId int) ([]cart.Item, *helper.AppError) {
@@ -49,16 +59,21 @@ func (c *cartServiceImpl) GetItems(ctx context.Context, userId int) ([]cart.Item
 	return cartItems, nil
 }
 
-func (c *cartServiceImpl) AddItem(ctx context.Context, userId int, productId string, quantity int) (cart.Item, *helper.AppError) {
+func (c *cartServiceImpl) AddItem(ctx context.Context, userId int, productId string, variantId string, quantity int) (cart.Item, *helper.AppError) {
 	var result *cart.Item
 
 	err := c.tx.ExecTx(ctx, func(ctx context.Context) error {
+		variant, err := resolveVariant(ctx, c.productVariantRepository, productId, variantId)
+		if err != nil {
+			return err
+		}
+
 		cartData, err := c.cartRepository.FindOrCreateByUserId(ctx, userId)
 		if err != nil {
 			return err
 		}
 
-		cartItem, err := c.cartItemRepository.FindByCartAndProductId(ctx, cartData.ID, productId)
+		cartItem, err := c.cartItemRepository.FindByCartAndVariantId(ctx, cartData.ID, variant.ID)
 		if err != nil {
 			return err
 		}
@@ -83,7 +98,8 @@ func (c *cartServiceImpl) AddItem(ctx context.Context, userId int, productId str
 
 		result = &cart.Item{
 			CartID:    cartData.ID,
-			ProductID: productId,
+			ProductID: variant.ProductID,
+			VariantID: variant.ID,
 			Quantity:  quantity,
 		}
 		err = c.cartItemRepository.Create(ctx, result)
@@ -103,6 +119,30 @@ func (c *cartServiceImpl) AddItem(ctx context.Context, userId int, productId str
 			)
 		}
 
+		if errors.Is(err, helper.ErrProductNotFound) {
+			return cart.Item{}, helper.NewAppError(
+				http.StatusNotFound,
+				"Product Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrVariantNotFound) {
+			return cart.Item{}, helper.NewAppError(
+				http.StatusNotFound,
+				"Variant Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrVariantRequired) {
+			return cart.Item{}, helper.NewAppError(
+				http.StatusBadRequest,
+				"Invalid Request",
+				err,
+			)
+		}
+
 		return cart.Item{}, helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -197,3 +237,125 @@ func (c *cartServiceImpl) DeleteItem(ctx context.Context, userId int, itemId int
 
 	return nil
 }
+
+// Checkout turns the user's cart into an order. Every line is validated before
+// anything is written so the caller gets the full list of problem lines at
+// once; the order is then placed through order.Service in the same
+// transaction and the cart is emptied.
+func (c *cartServiceImpl) Checkout(ctx context.Context, userId int, checkout order.Checkout) (order.Detail, *helper.AppError) {
+	var orderDetail order.Detail
+
+	err := c.tx.ExecTx(ctx, func(ctx context.Context) error {
+		cartData, err := c.cartRepository.FindByUserId(ctx, userId)
+		if err != nil {
+			if errors.Is(err, helper.ErrCartNotFound) {
+				return helper.ErrCartEmpty
+			}
+			return err
+		}
+
+		cartItems, err := c.cartItemRepository.FindAllByCartId(ctx, cartData.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(cartItems) == 0 {
+			return helper.ErrCartEmpty
+		}
+
+		var variantIds []string
+		for _, cartItem := range cartItems {
+			variantIds = append(variantIds, cartItem.VariantID)
+		}
+
+		// Lock up front so the stock validated here is still there when the
+		// order service reserves it in the same transaction.
+		variants, err := c.productVariantRepository.FindForUpdate(ctx, variantIds)
+		if err != nil {
+			return err
+		}
+
+		variantsById := make(map[string]product.Variant, len(variants))
+		for _, variant := range variants {
+			variantsById[variant.ID] = variant
+		}
+
+		var issues []cart.CheckoutIssue
+		var newItems []order.NewItem
+		for _, cartItem := range cartItems {
+			variant, ok := variantsById[cartItem.VariantID]
+			if !ok {
+				issues = append(issues, cart.CheckoutIssue{
+					CartItemID: cartItem.ID,
+					ProductID:  cartItem.ProductID,
+					VariantID:  cartItem.VariantID,
+					Quantity:   cartItem.Quantity,
+					Reason:     helper.ErrVariantNotFound,
+				})
+				continue
+			}
+
+			if variant.Stock < cartItem.Quantity {
+				issues = append(issues, cart.CheckoutIssue{
+					CartItemID: cartItem.ID,
+					ProductID:  cartItem.ProductID,
+					VariantID:  cartItem.VariantID,
+					Quantity:   cartItem.Quantity,
+					Available:  variant.Stock,
+					Reason:     helper.ErrProductInsufficientStock,
+				})
+				continue
+			}
+
+			newItems = append(newItems, order.NewItem{
+				ProductID: cartItem.ProductID,
+				VariantID: cartItem.VariantID,
+				Quantity:  cartItem.Quantity,
+			})
+		}
+
+		if len(issues) > 0 {
+			return &cart.CheckoutError{Issues: issues}
+		}
+
+		var appErr *helper.AppError
+		orderDetail, appErr = c.orderService.Create(ctx, userId, newItems, checkout)
+		if appErr != nil {
+			return appErr
+		}
+
+		return c.cartItemRepository.DeleteAllByCartId(ctx, cartData.ID)
+	})
+
+	if err != nil {
+		var checkoutErr *cart.CheckoutError
+		if errors.As(err, &checkoutErr) {
+			return order.Detail{}, helper.NewAppError(
+				http.StatusConflict,
+				"Cart Cannot Be Checked Out",
+				checkoutErr,
+			)
+		}
+
+		var appErr *helper.AppError
+		if errors.As(err, &appErr) {
+			return order.Detail{}, appErr
+		}
+
+		if errors.Is(err, helper.ErrCartEmpty) {
+			return order.Detail{}, helper.NewAppError(
+				http.StatusBadRequest,
+				"Cart Is Empty",
+				err,
+			)
+		}
+
+		return order.Detail{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return orderDetail, nil
+}
@@ -9,11 +9,12 @@ import (
 )
 
 type categoryServiceImpl struct {
+	tx                 *helper.Transaction
 	categoryRepository category.Repository
 }
 
-func NewCategory(categoryRepository category.Repository) category.Service {
-	return &categoryServiceImpl{categoryRepository: categoryRepository}
+func NewCategory(tx *helper.Transaction, categoryRepository category.Repository) category.Service {
+	return &categoryServiceImpl{tx: tx, categoryRepository: categoryRepository}
 }
 
 func (c *categoryServiceImpl) Create(ctx context.Context, data *category.Data) *helper.AppError {
@@ -27,6 +28,14 @@ func (c *categoryServiceImpl) Create(ctx context.Context, data *category.Data) *
 			)
 		}
 
+		if errors.Is(err, helper.ErrCategoryParentNotFound) {
+			return helper.NewAppError(
+				http.StatusUnprocessableEntity,
+				"Parent Category Not Found",
+				err,
+			)
+		}
+
 		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -37,29 +46,42 @@ func (c *categoryServiceImpl) Create(ctx context.Context, data *category.Data) *
 	return nil
 }
 
-func (c *categoryServiceImpl) Get(ctx context.Context, id string) (category.Data, *helper.AppError) {
-	categoryData, err := c.categoryRepository.Find(ctx, id)
+func (c *categoryServiceImpl) Get(ctx context.Context, id string) (category.Detail, *helper.AppError) {
+	path, err := c.categoryRepository.FindPath(ctx, id)
 	if err != nil {
 		if errors.Is(err, helper.ErrCategoryNotFound) {
-			return category.Data{}, helper.NewAppError(
+			return category.Detail{}, helper.NewAppError(
 				http.StatusNotFound,
 				"Product Not Found",
 				err,
 			)
 		}
 
-		return category.Data{}, helper.NewAppError(
+		return category.Detail{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return category.Detail{Data: path[len(path)-1], Path: path}, nil
+}
+
+func (c *categoryServiceImpl) GetAll(ctx context.Context, page helper.PageRequest) ([]category.Data, helper.Pagination, *helper.AppError) {
+	categories, total, err := c.categoryRepository.FindAll(ctx, page)
+	if err != nil {
+		return nil, helper.Pagination{}, helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
 			err,
 		)
 	}
 
-	return categoryData, nil
+	return categories, helper.NewPagination(page, total), nil
 }
 
-func (c *categoryServiceImpl) GetAll(ctx context.Context) ([]category.Data, *helper.AppError) {
-	categories, err := c.categoryRepository.FindAll(ctx)
+func (c *categoryServiceImpl) GetTree(ctx context.Context) ([]category.Node, *helper.AppError) {
+	categories, err := c.categoryRepository.FindEvery(ctx)
 	if err != nil {
 		return nil, helper.NewAppError(
 			http.StatusInternalServerError,
@@ -68,11 +90,54 @@ func (c *categoryServiceImpl) GetAll(ctx context.Context) ([]category.Data, *hel
 		)
 	}
 
-	return categories, nil
+	children := map[string][]category.Data{}
+	for _, categoryData := range categories {
+		children[categoryData.ParentID] = append(children[categoryData.ParentID], categoryData)
+	}
+
+	return buildCategoryTree(children, ""), nil
+}
+
+// buildCategoryTree nests the categories below parentId, keeping the name
+// order children already has.
+func buildCategoryTree(children map[string][]category.Data, parentId string) []category.Node {
+	nodes := []category.Node{}
+	for _, categoryData := range children[parentId] {
+		nodes = append(nodes, category.Node{
+			Data:     categoryData,
+			Children: buildCategoryTree(children, categoryData.ID),
+		})
+	}
+
+	return nodes
 }
 
+// Update refuses to move a category below itself or any of its subcategories.
 func (c *categoryServiceImpl) Update(ctx context.Context, update *category.Update) *helper.AppError {
-	err := c.categoryRepository.Update(ctx, update)
+	err := c.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if update.ParentID != nil && *update.ParentID != "" {
+			if err := c.categoryRepository.LockTree(ctx); err != nil {
+				return err
+			}
+
+			ancestors, err := c.categoryRepository.FindPath(ctx, *update.ParentID)
+			if err != nil {
+				if errors.Is(err, helper.ErrCategoryNotFound) {
+					return helper.ErrCategoryParentNotFound
+				}
+				return err
+			}
+
+			for _, ancestor := range ancestors {
+				if ancestor.ID == update.ID {
+					return helper.ErrCategoryCycle
+				}
+			}
+		}
+
+		return c.categoryRepository.Update(ctx, update)
+	})
+
 	if err != nil {
 		if errors.Is(err, helper.ErrCategoryNotFound) {
 			return helper.NewAppError(
@@ -82,6 +147,30 @@ func (c *categoryServiceImpl) Update(ctx context.Context, update *category.Updat
 			)
 		}
 
+		if errors.Is(err, helper.ErrCategoryAlreadyExists) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Category Already Exists",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrCategoryParentNotFound) {
+			return helper.NewAppError(
+				http.StatusUnprocessableEntity,
+				"Parent Category Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrCategoryCycle) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Category Cycle",
+				err,
+			)
+		}
+
 		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -103,6 +192,14 @@ func (c *categoryServiceImpl) Delete(ctx context.Context, id string) *helper.App
 			)
 		}
 
+		if errors.Is(err, helper.ErrCategoryNotEmpty) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Category Not Empty",
+				err,
+			)
+		}
+
 		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/idempotency"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+	"time"
+)
+
+type idempotencyServiceImpl struct {
+	idempotencyRepository idempotency.Repository
+	ttl                   time.Duration
+	lease                 time.Duration
+}
+
+// NewIdempotency remembers every key for ttl after its first use. A request
+// still unfinished after lease loses the key to the next retry.
+func NewIdempotency(idempotencyRepository idempotency.Repository, ttl time.Duration, lease time.Duration) idempotency.Service {
+	return &idempotencyServiceImpl{idempotencyRepository: idempotencyRepository, ttl: ttl, lease: lease}
+}
+
+func (i *idempotencyServiceImpl) Begin(ctx context.Context, userId int, key string, fingerprint string) (idempotency.Record, *helper.AppError) {
+	if key == "" || len(key) > 255 {
+		return idempotency.Record{}, helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Idempotency Key",
+			helper.ErrIdempotencyKeyInvalid,
+		)
+	}
+
+	now := time.Now()
+	record, reserved, err := i.idempotencyRepository.Reserve(ctx, idempotency.Record{
+		UserID:      userId,
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(i.ttl),
+		LockedUntil: now.Add(i.lease),
+	})
+	if err == nil && !reserved {
+		switch {
+		case record.Fingerprint != fingerprint:
+			err = helper.ErrIdempotencyKeyMismatch
+		case !record.Completed():
+			err = helper.ErrIdempotencyKeyInProgress
+		}
+	}
+
+	if err != nil {
+		if errors.Is(err, helper.ErrIdempotencyKeyMismatch) {
+			return idempotency.Record{}, helper.NewAppError(
+				http.StatusConflict,
+				"Idempotency Key Reused",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrIdempotencyKeyInProgress) {
+			return idempotency.Record{}, helper.NewAppError(
+				http.StatusConflict,
+				"Request In Progress",
+				err,
+			)
+		}
+
+		return idempotency.Record{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return record, nil
+}
+
+func (i *idempotencyServiceImpl) Complete(ctx context.Context, userId int, key string, statusCode int, body []byte) *helper.AppError {
+	if err := i.idempotencyRepository.Complete(ctx, userId, key, statusCode, body); err != nil {
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return nil
+}
+
+func (i *idempotencyServiceImpl) Release(ctx context.Context, userId int, key string) *helper.AppError {
+	if err := i.idempotencyRepository.Release(ctx, userId, key); err != nil {
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return nil
+}
+
+func (i *idempotencyServiceImpl) PurgeExpired(ctx context.Context) (int64, *helper.AppError) {
+	deleted, err := i.idempotencyRepository.DeleteExpired(ctx)
+	if err != nil {
+		return 0, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return deleted, nil
+}
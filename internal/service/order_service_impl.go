@@ -3,61 +3,152 @@ package service
 import (
 	"context"
 	"errors"
+	"mini-ecommerce/internal/domain/address"
 	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/domain/pricing"
 	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/domain/shipment"
+	"mini-ecommerce/internal/domain/user"
 	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
 	"net/http"
 )
 
 type orderServiceImpl struct {
-	tx                  *helper.Transaction
-	orderRepository     order.Repository
-	orderItemRepository order.ItemRepository
-	productRepository   product.Repository
+	tx                       *helper.Transaction
+	orderRepository          order.Repository
+	orderItemRepository      order.ItemRepository
+	productVariantRepository product.VariantRepository
+	promotionService         promotion.Service
+	pricingService           pricing.Service
+	addressRepository        address.Repository
+	shipmentRepository       shipment.Repository
 }
 
-func NewOrder(tx *helper.Transaction, orderRepository order.Repository, orderItemRepository order.ItemRepository) order.Service {
-	return &orderServiceImpl{tx: tx, orderRepository: orderRepository, orderItemRepository: orderItemRepository}
+func NewOrder(tx *helper.Transaction, orderRepository order.Repository, orderItemRepository order.ItemRepository, productVariantRepository product.VariantRepository, promotionService promotion.Service, pricingService pricing.Service, addressRepository address.Repository, shipmentRepository shipment.Repository) order.Service {
+	return &orderServiceImpl{tx: tx, orderRepository: orderRepository, orderItemRepository: orderItemRepository, productVariantRepository: productVariantRepository, promotionService: promotionService, pricingService: pricingService, addressRepository: addressRepository, shipmentRepository: shipmentRepository}
 }
 
-func (o *orderServiceImpl) Create(ctx context.Context, userId int, newItems []order.NewItem) (order.Detail, *helper.AppError) {
+// Create prices the order in a fixed sequence: the subtotal of the items, the
+// coupon discount, shipping by weight and region, tax by region and category,
+// and finally the total. The region is that of the shipping address, which is
+// copied onto the order.
+func (o *orderServiceImpl) Create(ctx context.Context, userId int, newItems []order.NewItem, checkout order.Checkout) (order.Detail, *helper.AppError) {
 	var orderDetail order.Detail
 	err := o.tx.ExecTx(ctx, func(ctx context.Context) error {
-		var totalPrice float64
+		if len(newItems) == 0 {
+			return helper.ErrOrderEmpty
+		}
+
+		newItems = append([]order.NewItem(nil), newItems...)
+		quantities := map[string]int{}
+		var variantIds []string
+		for i := range newItems {
+			if newItems[i].VariantID == "" {
+				variant, err := resolveVariant(ctx, o.productVariantRepository, newItems[i].ProductID, "")
+				if err != nil {
+					return err
+				}
+				newItems[i].VariantID = variant.ID
+			}
+
+			variantId := newItems[i].VariantID
+			if _, ok := quantities[variantId]; !ok {
+				variantIds = append(variantIds, variantId)
+			}
+			quantities[variantId] += newItems[i].Quantity
+		}
+
+		variants, err := o.lockVariants(ctx, variantIds)
+		if err != nil {
+			return err
+		}
+
+		var subtotal money.Money
+		var weightGrams int
 
 		var orderItems []order.Item
 		for _, newItem := range newItems {
-			productData, err := o.productRepository.Find(ctx, newItem.ProductID)
-			if err != nil {
-				return err
+			variant := variants[newItem.VariantID]
+			if newItem.ProductID != "" && variant.ProductID != newItem.ProductID {
+				return helper.ErrVariantNotFound
 			}
 
-			if productData.Stock < newItem.Quantity {
+			if variant.Stock < quantities[variant.ID] {
 				return helper.ErrProductInsufficientStock
 			}
 
-			itemPrice := productData.Price * float64(newItem.Quantity)
-			totalPrice += itemPrice
+			if len(orderItems) == 0 {
+				subtotal = money.Zero(variant.Price.Currency)
+			}
+			if !subtotal.SameCurrency(variant.Price) {
+				return helper.ErrCurrencyMismatch
+			}
 
 			orderItem := order.Item{
-				ProductID: newItem.ProductID,
-				Price:     productData.Price,
+				ProductID: variant.ProductID,
+				VariantID: variant.ID,
+				SKU:       variant.SKU,
+				Price:     variant.Price,
 				Quantity:  newItem.Quantity,
 			}
 
 			orderItems = append(orderItems, orderItem)
+			subtotal = subtotal.Add(orderItem.LineTotal())
+			weightGrams += variant.WeightGrams * newItem.Quantity
+		}
+
+		shippingAddress, err := o.shippingAddress(ctx, userId, checkout.AddressID)
+		if err != nil {
+			return err
 		}
 
 		orderData := order.Data{
-			UserID:     userId,
-			TotalPrice: totalPrice,
-			Status:     order.StatusPending,
+			UserID:          userId,
+			Subtotal:        subtotal,
+			Discount:        money.Zero(subtotal.Currency),
+			Refunded:        money.Zero(subtotal.Currency),
+			Region:          shippingAddress.Region,
+			ShippingAddress: shippingAddress,
+			Status:          order.StatusPending,
+		}
+
+		var discount promotion.Discount
+		if checkout.CouponCode != "" {
+			lines := make([]promotion.Line, 0, len(orderItems))
+			for _, orderItem := range orderItems {
+				lines = append(lines, promotion.Line{
+					ProductID: orderItem.ProductID,
+					Total:     orderItem.LineTotal(),
+				})
+			}
+
+			var appErr *helper.AppError
+			discount, appErr = o.promotionService.Apply(ctx, userId, checkout.CouponCode, lines)
+			if appErr != nil {
+				return appErr
+			}
+
+			orderData.Discount = discount.Amount
+			orderData.CouponCode = discount.Code
+			orderData.FreeShipping = discount.FreeShipping
+		}
+
+		if appErr := o.price(ctx, &orderData, orderItems, discount.Shares, weightGrams); appErr != nil {
+			return appErr
 		}
 
 		if err := o.orderRepository.Create(ctx, &orderData); err != nil {
 			return err
 		}
 
+		if checkout.CouponCode != "" {
+			if appErr := o.promotionService.Redeem(ctx, userId, orderData.ID, discount); appErr != nil {
+				return appErr
+			}
+		}
+
 		for i := range orderItems {
 			orderItems[i].OrderID = orderData.ID
 		}
@@ -67,24 +158,24 @@ func (o *orderServiceImpl) Create(ctx context.Context, userId int, newItems []or
 		}
 
 		for _, orderItem := range orderItems {
-			if err := o.productRepository.UpdateStock(ctx, orderItem.ProductID, orderItem.Quantity); err != nil {
+			if err := o.productVariantRepository.UpdateStock(ctx, orderItem.VariantID, orderItem.Quantity); err != nil {
 				return err
 			}
 		}
 
 		orderDetail = order.Detail{
-			Data: order.Data{
-				ID:         orderData.ID,
-				UserID:     orderData.UserID,
-				TotalPrice: orderData.TotalPrice,
-				Status:     orderData.Status,
-			},
+			Data:  orderData,
 			Items: orderItems,
 		}
 		return nil
 	})
 
 	if err != nil {
+		var appErr *helper.AppError
+		if errors.As(err, &appErr) {
+			return orderDetail, appErr
+		}
+
 		if errors.Is(err, helper.ErrProductNotFound) {
 			return orderDetail, helper.NewAppError(
 				http.StatusNotFound,
@@ -93,6 +184,38 @@ func (o *orderServiceImpl) Create(ctx context.Context, userId int, newItems []or
 			)
 		}
 
+		if errors.Is(err, helper.ErrVariantNotFound) {
+			return orderDetail, helper.NewAppError(
+				http.StatusNotFound,
+				"Variant Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrAddressNotFound) {
+			return orderDetail, helper.NewAppError(
+				http.StatusNotFound,
+				"Address Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrAddressRequired) {
+			return orderDetail, helper.NewAppError(
+				http.StatusBadRequest,
+				"Shipping Address Required",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrVariantRequired) {
+			return orderDetail, helper.NewAppError(
+				http.StatusBadRequest,
+				"Invalid Request",
+				err,
+			)
+		}
+
 		if errors.Is(err, helper.ErrProductInsufficientStock) {
 			return orderDetail, helper.NewAppError(
 				http.StatusConflict,
@@ -101,6 +224,22 @@ func (o *orderServiceImpl) Create(ctx context.Context, userId int, newItems []or
 			)
 		}
 
+		if errors.Is(err, helper.ErrCurrencyMismatch) {
+			return orderDetail, helper.NewAppError(
+				http.StatusConflict,
+				"Currency Mismatch",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrOrderEmpty) {
+			return orderDetail, helper.NewAppError(
+				http.StatusBadRequest,
+				"Invalid Request",
+				err,
+			)
+		}
+
 		return orderDetail, helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -111,8 +250,12 @@ func (o *orderServiceImpl) Create(ctx context.Context, userId int, newItems []or
 	return orderDetail, nil
 }
 
-func (o *orderServiceImpl) Get(ctx context.Context, id int) (order.Detail, *helper.AppError) {
+func (o *orderServiceImpl) Get(ctx context.Context, caller user.Caller, id int) (order.Detail, *helper.AppError) {
 	orderData, err := o.orderRepository.FindById(ctx, id)
+	if err == nil && !caller.Owns(orderData.UserID) {
+		err = helper.ErrOrderNotFound
+	}
+
 	if err != nil {
 		if errors.Is(err, helper.ErrOrderNotFound) {
 			return order.Detail{}, helper.NewAppError(
@@ -138,21 +281,26 @@ func (o *orderServiceImpl) Get(ctx context.Context, id int) (order.Detail, *help
 		)
 	}
 
+	shipments, err := o.shipmentRepository.FindByOrderId(ctx, id)
+	if err != nil {
+		return order.Detail{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
 	return order.Detail{
-		Data: order.Data{
-			ID:         orderData.ID,
-			UserID:     orderData.UserID,
-			TotalPrice: orderData.TotalPrice,
-			Status:     orderData.Status,
-		},
-		Items: orderItems,
+		Data:      orderData,
+		Items:     orderItems,
+		Shipments: shipments,
 	}, nil
 }
 
-func (o *orderServiceImpl) GetByUserId(ctx context.Context, userId int) ([]order.Detail, *helper.AppError) {
-	orders, err := o.orderRepository.FindByUserId(ctx, userId)
+func (o *orderServiceImpl) GetByUserId(ctx context.Context, userId int, page helper.PageRequest) ([]order.Detail, helper.Pagination, *helper.AppError) {
+	orders, total, err := o.orderRepository.FindByUserId(ctx, userId, page)
 	if err != nil {
-		return nil, helper.NewAppError(
+		return nil, helper.Pagination{}, helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
 			err,
@@ -163,7 +311,16 @@ func (o *orderServiceImpl) GetByUserId(ctx context.Context, userId int) ([]order
 	for _, orderData := range orders {
 		orderItems, err := o.orderItemRepository.FindItems(ctx, orderData.ID)
 		if err != nil {
-			return nil, helper.NewAppError(
+			return nil, helper.Pagination{}, helper.NewAppError(
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				err,
+			)
+		}
+
+		shipments, err := o.shipmentRepository.FindByOrderId(ctx, orderData.ID)
+		if err != nil {
+			return nil, helper.Pagination{}, helper.NewAppError(
 				http.StatusInternalServerError,
 				"Internal Server Error",
 				err,
@@ -171,24 +328,82 @@ func (o *orderServiceImpl) GetByUserId(ctx context.Context, userId int) ([]order
 		}
 
 		orderDetail := order.Detail{
-			Data: order.Data{
-				ID:         orderData.ID,
-				UserID:     orderData.UserID,
-				TotalPrice: orderData.TotalPrice,
-				Status:     orderData.Status,
-			},
-			Items: orderItems,
+			Data:      orderData,
+			Items:     orderItems,
+			Shipments: shipments,
 		}
 
 		orderDetails = append(orderDetails, orderDetail)
 	}
 
-	return orderDetails, nil
+	return orderDetails, helper.NewPagination(page, total), nil
 }
 
-func (o *orderServiceImpl) UpdateStatus(ctx context.Context, id int, status order.Status) *helper.AppError {
-	_, err := o.orderRepository.FindById(ctx, id)
+func (o *orderServiceImpl) UpdateStatus(ctx context.Context, caller user.Caller, id int, status order.Status) *helper.AppError {
+	return o.transition(ctx, caller, id, status)
+}
+
+func (o *orderServiceImpl) Cancel(ctx context.Context, caller user.Caller, id int) *helper.AppError {
+	return o.transition(ctx, caller, id, order.StatusCancelled)
+}
+
+// transition moves an order to next after checking it against the order
+// state machine. Every status change made by this service goes through here,
+// and moving to cancelled puts the reserved stock and any coupon use back in
+// the same transaction. The order row stays locked throughout, so concurrent
+// changes to one order are checked against each other's result.
+// Orders the caller does not own are reported as not found.
+func (o *orderServiceImpl) transition(ctx context.Context, caller user.Caller, id int, next order.Status) *helper.AppError {
+	err := o.tx.ExecTx(ctx, func(ctx context.Context) error {
+		orderData, err := o.orderRepository.FindByIdForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !caller.Owns(orderData.UserID) {
+			return helper.ErrOrderNotFound
+		}
+
+		if err := order.ValidateTransition(orderData.Status, next); err != nil {
+			return err
+		}
+
+		// A charge in flight may still succeed; cancelling under it would
+		// leave a paid charge on a cancelled order.
+		if next == order.StatusCancelled {
+			pending, err := o.orderRepository.HasPendingPayment(ctx, orderData.ID)
+			if err != nil {
+				return err
+			}
+
+			if pending {
+				return helper.ErrPaymentInProgress
+			}
+		}
+
+		if err := o.orderRepository.UpdateStatus(ctx, orderData.ID, next); err != nil {
+			return err
+		}
+
+		if next == order.StatusCancelled {
+			if err := o.restoreStock(ctx, orderData.ID); err != nil {
+				return err
+			}
+
+			if appErr := o.promotionService.Release(ctx, orderData.ID); appErr != nil {
+				return appErr
+			}
+		}
+
+		return nil
+	})
+
 	if err != nil {
+		var appErr *helper.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+
 		if errors.Is(err, helper.ErrOrderNotFound) {
 			return helper.NewAppError(
 				http.StatusNotFound,
@@ -197,18 +412,34 @@ func (o *orderServiceImpl) UpdateStatus(ctx context.Context, id int, status orde
 			)
 		}
 
-		return helper.NewAppError(
-			http.StatusInternalServerError,
-			"Internal Server Error",
-			err,
-		)
-	}
-
-	if err := o.orderRepository.UpdateStatus(ctx, id, status); err != nil {
-		if errors.Is(err, helper.ErrOrderNotFound) {
+		if errors.Is(err, helper.ErrProductNotFound) {
 			return helper.NewAppError(
 				http.StatusNotFound,
-				"Order Not Found",
+				"Product Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrOrderInvalidStatus) {
+			return helper.NewAppError(
+				http.StatusBadRequest,
+				"Invalid Order Status",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrOrderInvalidTransition) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Invalid Status Transition",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrPaymentInProgress) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Order Cannot Be Cancelled",
 				err,
 			)
 		}
@@ -223,46 +454,111 @@ func (o *orderServiceImpl) UpdateStatus(ctx context.Context, id int, status orde
 	return nil
 }
 
-func (o *orderServiceImpl) Cancel(ctx context.Context, id int) *helper.AppError {
-	orderData, err := o.orderRepository.FindById(ctx, id)
+// shippingAddress returns the address of userId an order ships to: addressId,
+// or the default address when it is empty. Without either the order has
+// nowhere to ship to, nor a region to tax by, so helper.ErrAddressRequired is
+// returned.
+func (o *orderServiceImpl) shippingAddress(ctx context.Context, userId int, addressId string) (*address.Address, error) {
+	if addressId != "" {
+		addressData, err := o.addressRepository.FindById(ctx, userId, addressId)
+		if err != nil {
+			return nil, err
+		}
+		return &addressData.Address, nil
+	}
+
+	addressData, err := o.addressRepository.FindDefault(ctx, userId)
+	if errors.Is(err, helper.ErrAddressNotFound) {
+		return nil, helper.ErrAddressRequired
+	}
 	if err != nil {
-		if errors.Is(err, helper.ErrOrderNotFound) {
-			return helper.NewAppError(
-				http.StatusNotFound,
-				"Order Not Found",
-				err,
-			)
+		return nil, err
+	}
+
+	return &addressData.Address, nil
+}
+
+// price adds shipping, tax and the total to orderData, which already has its
+// subtotal and discount. Tax is charged on each item after its share of the
+// discount, as split by the coupon over the items it applies to; discountShares
+// is nil without a coupon. Shipping is not taxed.
+func (o *orderServiceImpl) price(ctx context.Context, orderData *order.Data, orderItems []order.Item, discountShares []money.Money, weightGrams int) *helper.AppError {
+	currency := orderData.Subtotal.Currency
+
+	orderData.Shipping = money.Zero(currency)
+	if !orderData.FreeShipping {
+		shipping, appErr := o.pricingService.Shipping(ctx, orderData.Region, currency, weightGrams)
+		if appErr != nil {
+			return appErr
 		}
+		orderData.Shipping = shipping
+	}
 
-		return helper.NewAppError(
-			http.StatusInternalServerError,
-			"Internal Server Error",
-			err,
-		)
+	lines := make([]pricing.Line, 0, len(orderItems))
+	for i, orderItem := range orderItems {
+		total := orderItem.LineTotal()
+		if discountShares != nil {
+			total = total.Sub(discountShares[i])
+		}
+
+		lines = append(lines, pricing.Line{
+			ProductID: orderItem.ProductID,
+			Total:     total,
+		})
 	}
 
-	if orderData.Status != order.StatusPending {
-		return helper.NewAppError(
-			http.StatusConflict,
-			"Order Cannot Be Cancelled",
-			errors.New("Only pending orders can be cancelled"),
-		)
+	tax, appErr := o.pricingService.Tax(ctx, orderData.Region, lines)
+	if appErr != nil {
+		return appErr
 	}
+	orderData.Tax = tax
 
-	if err := o.orderRepository.UpdateStatus(ctx, orderData.ID, orderData.Status); err != nil {
-		if errors.Is(err, helper.ErrOrderNotFound) {
-			return helper.NewAppError(
-				http.StatusNotFound,
-				"Order Not Found",
-				err,
-			)
+	orderData.TotalPrice = orderData.Subtotal.Sub(orderData.Discount).Add(orderData.Shipping).Add(orderData.Tax)
+	return nil
+}
+
+// lockVariants row locks every variant in variantIds for the rest of the
+// transaction and returns them keyed by id. All stock changes take their locks
+// here first, in id order, so concurrent orders cannot deadlock or oversell.
+func (o *orderServiceImpl) lockVariants(ctx context.Context, variantIds []string) (map[string]product.Variant, error) {
+	variants, err := o.productVariantRepository.FindForUpdate(ctx, variantIds)
+	if err != nil {
+		return nil, err
+	}
+
+	variantsById := make(map[string]product.Variant, len(variants))
+	for _, variant := range variants {
+		variantsById[variant.ID] = variant
+	}
+
+	for _, id := range variantIds {
+		if _, ok := variantsById[id]; !ok {
+			return nil, helper.ErrVariantNotFound
 		}
+	}
 
-		return helper.NewAppError(
-			http.StatusInternalServerError,
-			"Internal Server Error",
-			err,
-		)
+	return variantsById, nil
+}
+
+func (o *orderServiceImpl) restoreStock(ctx context.Context, orderId int) error {
+	orderItems, err := o.orderItemRepository.FindItems(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	var variantIds []string
+	for _, orderItem := range orderItems {
+		variantIds = append(variantIds, orderItem.VariantID)
+	}
+
+	if _, err := o.lockVariants(ctx, variantIds); err != nil {
+		return err
+	}
+
+	for _, orderItem := range orderItems {
+		if err := o.productVariantRepository.IncreaseStock(ctx, orderItem.VariantID, orderItem.Quantity); err != nil {
+			return err
+		}
 	}
 
 	return nil
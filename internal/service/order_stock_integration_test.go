@@ -0,0 +1,205 @@
+//go:build integration
+
+// Run with a disposable database; pending migrations are applied to it:
+//
+//	TEST_DATABASE_URL=postgres://... go test -tags integration ./internal/service/
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mini-ecommerce/internal/config"
+	"mini-ecommerce/internal/database"
+	"mini-ecommerce/internal/database/migrations"
+	"mini-ecommerce/internal/domain/address"
+	"mini-ecommerce/internal/domain/category"
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/domain/pricing"
+	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"mini-ecommerce/internal/repository"
+	"mini-ecommerce/internal/service"
+	"mini-ecommerce/internal/storage"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	raceOrders   = 200
+	raceStock    = 50
+	raceQuantity = 1
+	raceCurrency = "USD"
+)
+
+// TestOrderCreateDoesNotOversell fires many concurrent orders at a single
+// product with limited stock, placed through the order service as the API
+// does, and checks that exactly the available stock was sold.
+func TestOrderCreateDoesNotOversell(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	db, err := database.Connect(ctx, config.Database{URL: url, MaxConns: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	migrator, err := database.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	blob, err := storage.NewLocal(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := helper.NewTransaction(db)
+	productVariantRepository := repository.NewProductVariant(tx)
+	productService := service.NewProduct(tx, repository.NewProduct(tx), productVariantRepository, repository.NewProductImage(tx), repository.NewCategory(tx), blob, raceCurrency)
+	promotionService := service.NewPromotion(tx, repository.NewCoupon(tx), repository.NewCouponRedemption(tx), raceCurrency)
+	pricingService := service.NewPricing(tx, repository.NewShippingRate(tx), repository.NewTaxRate(tx), raceCurrency)
+	orderService := service.NewOrder(tx, repository.NewOrder(tx), repository.NewOrderItem(tx), productVariantRepository, promotionService, pricingService, repository.NewAddress(tx), repository.NewShipment(tx))
+
+	suffix := time.Now().UnixNano()
+	region := fmt.Sprintf("R%d", suffix%1_000_000_000_000)
+
+	rate := pricing.ShippingRate{Region: region, Fee: money.New(500, raceCurrency)}
+	if appErr := pricingService.CreateShippingRate(ctx, &rate); appErr != nil {
+		t.Fatal(appErr)
+	}
+
+	categoryData := category.Data{Name: fmt.Sprintf("stock-race-%d", suffix)}
+	if err := repository.NewCategory(tx).Create(ctx, &categoryData); err != nil {
+		t.Fatal(err)
+	}
+
+	detail := product.Detail{
+		Data: product.Data{
+			CategoryID:  categoryData.ID,
+			Name:        fmt.Sprintf("stock-race-%d", suffix),
+			Description: "Fixture for TestOrderCreateDoesNotOversell",
+			Price:       money.New(100, raceCurrency),
+			Stock:       raceStock,
+		},
+	}
+	if appErr := productService.Create(ctx, &detail); appErr != nil {
+		t.Fatal(appErr)
+	}
+	variant := detail.Variants[0]
+
+	userData := user.Data{
+		Name:     "Stock Race",
+		Email:    fmt.Sprintf("stock-race-%d@example.invalid", suffix),
+		Password: "-",
+	}
+	if err := repository.NewUser(db).Create(ctx, &userData); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		// Orders cascade from the user; order items must be gone before the
+		// product can be deleted.
+		for _, cleanup := range []struct {
+			query string
+			arg   any
+		}{
+			{"DELETE FROM users WHERE id = $1", userData.ID},
+			{"DELETE FROM products WHERE id = $1", detail.Data.ID},
+			{"DELETE FROM categories WHERE id = $1", categoryData.ID},
+			{"DELETE FROM shipping_rates WHERE id = $1", rate.ID},
+		} {
+			if _, err := db.Exec(context.Background(), cleanup.query, cleanup.arg); err != nil {
+				t.Errorf("clean up: %v", err)
+			}
+		}
+	})
+
+	addressData := address.Data{
+		UserID: userData.ID,
+		Address: address.Address{
+			Recipient:  "Stock Race",
+			Phone:      "000",
+			Line1:      "1 Test Street",
+			City:       "Test",
+			PostalCode: "00000",
+			Country:    "US",
+			Region:     region,
+		},
+		Default: true,
+	}
+	if err := repository.NewAddress(tx).Create(ctx, &addressData); err != nil {
+		t.Fatal(err)
+	}
+
+	var accepted, rejected atomic.Int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+
+	for i := 0; i < raceOrders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			_, appErr := orderService.Create(ctx, userData.ID, []order.NewItem{
+				{ProductID: detail.Data.ID, VariantID: variant.ID, Quantity: raceQuantity},
+			}, order.Checkout{})
+			switch {
+			case appErr == nil:
+				accepted.Add(1)
+			case errors.Is(appErr.Err, helper.ErrProductInsufficientStock):
+				rejected.Add(1)
+			default:
+				t.Errorf("unexpected error: %s: %v", appErr.Message, appErr)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	remaining, err := productVariantRepository.FindById(ctx, variant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ordered, orders int
+	if err := db.QueryRow(
+		ctx,
+		"SELECT COALESCE(SUM(quantity), 0), COUNT(DISTINCT order_id) FROM order_items WHERE variant_id = $1",
+		variant.ID,
+	).Scan(&ordered, &orders); err != nil {
+		t.Fatal(err)
+	}
+
+	want := min(raceOrders, raceStock/raceQuantity)
+	if got := int(accepted.Load()); got != want {
+		t.Errorf("accepted %d orders, want %d", got, want)
+	}
+	if got := int(rejected.Load()); got != raceOrders-want {
+		t.Errorf("rejected %d orders for stock, want %d", got, raceOrders-want)
+	}
+	if orders != want {
+		t.Errorf("stored %d orders, want %d", orders, want)
+	}
+	if ordered != want*raceQuantity {
+		t.Errorf("ordered %d units, want %d", ordered, want*raceQuantity)
+	}
+	if remaining.Stock != raceStock-want*raceQuantity {
+		t.Errorf("stock is %d, want %d", remaining.Stock, raceStock-want*raceQuantity)
+	}
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+	"time"
+)
+
+type paymentServiceImpl struct {
+	tx                *helper.Transaction
+	paymentRepository payment.Repository
+	orderRepository   order.Repository
+	gateway           payment.Gateway
+	gatewayTimeout    time.Duration
+	reconcileAfter    time.Duration
+}
+
+// NewPayment bounds each gateway call by gatewayTimeout. Reconcile retries
+// payments that have been pending for longer than reconcileAfter.
+func NewPayment(tx *helper.Transaction, paymentRepository payment.Repository, orderRepository order.Repository, gateway payment.Gateway, gatewayTimeout time.Duration, reconcileAfter time.Duration) payment.Service {
+	return &paymentServiceImpl{
+		tx:                tx,
+		paymentRepository: paymentRepository,
+		orderRepository:   orderRepository,
+		gateway:           gateway,
+		gatewayTimeout:    gatewayTimeout,
+		reconcileAfter:    reconcileAfter,
+	}
+}
+
+// Create charges the order in three steps so a charge is never made without a
+// record of it: the pending payment is committed with the order locked, the
+// gateway is called outside any transaction, and the result is then recorded
+// in a second transaction. A pending payment blocks further attempts on the
+// order until its result is in. When the gateway cannot tell how the charge
+// ended, the payment is returned still pending and Reconcile settles it later.
+func (p *paymentServiceImpl) Create(ctx context.Context, caller user.Caller, orderId int, method payment.Method) (payment.Data, *helper.AppError) {
+	var paymentData payment.Data
+
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		orderData, err := p.orderRepository.FindByIdForUpdate(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		if !caller.Owns(orderData.UserID) {
+			return helper.ErrOrderNotFound
+		}
+
+		if err := order.ValidateTransition(orderData.Status, order.StatusPaid); err != nil {
+			return fmt.Errorf("%w: %w", helper.ErrOrderNotPayable, err)
+		}
+
+		payments, err := p.paymentRepository.FindByOrderId(ctx, orderData.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, existing := range payments {
+			if existing.Status == payment.StatusPending {
+				return helper.ErrPaymentInProgress
+			}
+		}
+
+		paymentData = payment.Data{
+			OrderID: orderData.ID,
+			Method:  method,
+			Status:  payment.StatusPending,
+			Amount:  orderData.TotalPrice,
+		}
+		return p.paymentRepository.Create(ctx, &paymentData)
+	})
+
+	if err == nil {
+		err = p.settle(ctx, &paymentData)
+	}
+
+	if err != nil {
+		if errors.Is(err, helper.ErrOrderNotFound) {
+			return payment.Data{}, helper.NewAppError(
+				http.StatusNotFound,
+				"Order Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrOrderNotPayable) || errors.Is(err, helper.ErrPaymentInProgress) {
+			return payment.Data{}, helper.NewAppError(
+				http.StatusConflict,
+				"Order Cannot Be Paid",
+				err,
+			)
+		}
+
+		return payment.Data{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	if paymentData.Status == payment.StatusFailed {
+		return paymentData, helper.NewAppError(
+			http.StatusPaymentRequired,
+			"Payment Failed",
+			helper.ErrPaymentFailed,
+		)
+	}
+
+	return paymentData, nil
+}
+
+// settle charges paymentData through the gateway and records the result,
+// marking the order paid on success. A gateway error leaves the payment
+// pending, since the charge may still have gone through; repeating the charge
+// later returns its real outcome.
+func (p *paymentServiceImpl) settle(ctx context.Context, paymentData *payment.Data) error {
+	// The client may go away mid-charge; the charge and its record must
+	// still be seen through.
+	ctx = context.WithoutCancel(ctx)
+
+	chargeCtx, cancel := context.WithTimeout(ctx, p.gatewayTimeout)
+	result, err := p.gateway.Charge(chargeCtx, payment.Charge{
+		PaymentID: paymentData.ID,
+		OrderID:   paymentData.OrderID,
+		Method:    paymentData.Method,
+		Amount:    paymentData.Amount,
+	})
+	cancel()
+	if err != nil {
+		log.Printf("[PAYMENT] charge of payment %d left pending : %v", paymentData.ID, err)
+		return nil
+	}
+
+	paymentUpdate := payment.Update{
+		ID:        paymentData.ID,
+		Status:    result.Status,
+		Reference: result.Reference,
+	}
+	if result.Status == payment.StatusSuccess {
+		paidAt := time.Now()
+		paymentUpdate.PaidAt = &paidAt
+	}
+
+	return p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		orderData, err := p.orderRepository.FindByIdForUpdate(ctx, paymentData.OrderID)
+		if err != nil {
+			return err
+		}
+
+		// Reconcile may have settled the payment while the gateway was
+		// being called.
+		current, err := p.paymentRepository.FindById(ctx, paymentData.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != payment.StatusPending {
+			*paymentData = current
+			return nil
+		}
+
+		if err := p.paymentRepository.Update(ctx, &paymentUpdate); err != nil {
+			return err
+		}
+
+		paymentData.Status = paymentUpdate.Status
+		paymentData.Reference = paymentUpdate.Reference
+		paymentData.PaidAt = paymentUpdate.PaidAt
+
+		if paymentUpdate.Status != payment.StatusSuccess {
+			return nil
+		}
+
+		// The pending payment keeps the order from being cancelled, so it
+		// can still move to paid.
+		if err := order.ValidateTransition(orderData.Status, order.StatusPaid); err != nil {
+			return err
+		}
+
+		return p.orderRepository.UpdateStatus(ctx, orderData.ID, order.StatusPaid)
+	})
+}
+
+// Reconcile repeats the charge of every payment pending for longer than
+// reconcileAfter and records how it ended. It returns how many payments were
+// settled; those the gateway still cannot answer for stay pending.
+func (p *paymentServiceImpl) Reconcile(ctx context.Context) (int, *helper.AppError) {
+	payments, err := p.paymentRepository.FindPendingBefore(ctx, time.Now().Add(-p.reconcileAfter))
+	if err != nil {
+		return 0, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	settled := 0
+	var errs []error
+	for _, paymentData := range payments {
+		if err := p.settle(ctx, &paymentData); err != nil {
+			errs = append(errs, fmt.Errorf("payment %d : %w", paymentData.ID, err))
+			continue
+		}
+		if paymentData.Status != payment.StatusPending {
+			settled++
+		}
+	}
+
+	if len(errs) > 0 {
+		return settled, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			errors.Join(errs...),
+		)
+	}
+
+	return settled, nil
+}
+
+func (p *paymentServiceImpl) Get(ctx context.Context, caller user.Caller, id int) (payment.Data, *helper.AppError) {
+	var paymentData payment.Data
+
+	err := func() error {
+		var err error
+		paymentData, err = p.paymentRepository.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		orderData, err := p.orderRepository.FindById(ctx, paymentData.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if !caller.Owns(orderData.UserID) {
+			return helper.ErrPaymentNotFound
+		}
+
+		return nil
+	}()
+
+	if err != nil {
+		if errors.Is(err, helper.ErrPaymentNotFound) || errors.Is(err, helper.ErrOrderNotFound) {
+			return payment.Data{}, helper.NewAppError(
+				http.StatusNotFound,
+				"Payment Not Found",
+				err,
+			)
+		}
+
+		return payment.Data{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return paymentData, nil
+}
+
+func (p *paymentServiceImpl) GetByOrderId(ctx context.Context, caller user.Caller, orderId int) ([]payment.Data, *helper.AppError) {
+	var payments []payment.Data
+
+	err := func() error {
+		orderData, err := p.orderRepository.FindById(ctx, orderId)
+		if err != nil {
+			return err
+		}
+
+		if !caller.Owns(orderData.UserID) {
+			return helper.ErrOrderNotFound
+		}
+
+		payments, err = p.paymentRepository.FindByOrderId(ctx, orderData.ID)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}()
+
+	if err != nil {
+		if errors.Is(err, helper.ErrOrderNotFound) {
+			return nil, helper.NewAppError(
+				http.StatusNotFound,
+				"Order Not Found",
+				err,
+			)
+		}
+
+		return nil, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/pricing"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"net/http"
+	"strings"
+)
+
+type pricingServiceImpl struct {
+	tx                     *helper.Transaction
+	shippingRateRepository pricing.ShippingRateRepository
+	taxRateRepository      pricing.TaxRateRepository
+	currency               string
+}
+
+// NewPricing prices shipping rates without an explicit currency in currency,
+// the store currency.
+func NewPricing(tx *helper.Transaction, shippingRateRepository pricing.ShippingRateRepository, taxRateRepository pricing.TaxRateRepository, currency string) pricing.Service {
+	return &pricingServiceImpl{
+		tx:                     tx,
+		shippingRateRepository: shippingRateRepository,
+		taxRateRepository:      taxRateRepository,
+		currency:               currency,
+	}
+}
+
+// normalizeRegion makes region codes case-insensitive.
+func normalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+func (p *pricingServiceImpl) CreateShippingRate(ctx context.Context, rate *pricing.ShippingRate) *helper.AppError {
+	rate.Region = normalizeRegion(rate.Region)
+	if rate.Fee.Currency == "" {
+		rate.Fee = money.New(rate.Fee.Amount, p.currency)
+	}
+
+	if !money.IsSupportedCurrency(rate.Fee.Currency) {
+		return helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request",
+			helper.ErrCurrencyUnsupported,
+		)
+	}
+
+	return pricingAppError(p.shippingRateRepository.Create(ctx, rate))
+}
+
+func (p *pricingServiceImpl) GetShippingRates(ctx context.Context) ([]pricing.ShippingRate, *helper.AppError) {
+	rates, err := p.shippingRateRepository.FindAll(ctx)
+	if err != nil {
+		return nil, pricingAppError(err)
+	}
+
+	return rates, nil
+}
+
+func (p *pricingServiceImpl) DeleteShippingRate(ctx context.Context, id string) *helper.AppError {
+	return pricingAppError(p.shippingRateRepository.Delete(ctx, id))
+}
+
+func (p *pricingServiceImpl) CreateTaxRate(ctx context.Context, rate *pricing.TaxRate) *helper.AppError {
+	rate.Region = normalizeRegion(rate.Region)
+	return pricingAppError(p.taxRateRepository.Create(ctx, rate))
+}
+
+func (p *pricingServiceImpl) GetTaxRates(ctx context.Context) ([]pricing.TaxRate, *helper.AppError) {
+	rates, err := p.taxRateRepository.FindAll(ctx)
+	if err != nil {
+		return nil, pricingAppError(err)
+	}
+
+	return rates, nil
+}
+
+func (p *pricingServiceImpl) DeleteTaxRate(ctx context.Context, id string) *helper.AppError {
+	return pricingAppError(p.taxRateRepository.Delete(ctx, id))
+}
+
+func (p *pricingServiceImpl) Shipping(ctx context.Context, region string, currency string, weightGrams int) (money.Money, *helper.AppError) {
+	rate, err := p.shippingRateRepository.FindForWeight(ctx, normalizeRegion(region), currency, weightGrams)
+	if err != nil {
+		// Shipping is never given away for want of a rate; a rate without a
+		// region is the fallback for every region.
+		if errors.Is(err, helper.ErrShippingRateNotFound) {
+			return money.Money{}, helper.NewAppError(
+				http.StatusUnprocessableEntity,
+				"Shipping Not Available",
+				err,
+			)
+		}
+		return money.Money{}, pricingAppError(err)
+	}
+
+	return rate.Fee, nil
+}
+
+// Tax is exclusive: it is added on top of the lines and rounded per line.
+func (p *pricingServiceImpl) Tax(ctx context.Context, region string, lines []pricing.Line) (money.Money, *helper.AppError) {
+	if len(lines) == 0 {
+		return money.Money{}, nil
+	}
+
+	var productIds []string
+	for _, line := range lines {
+		productIds = append(productIds, line.ProductID)
+	}
+
+	basisPoints, err := p.taxRateRepository.FindForProducts(ctx, normalizeRegion(region), productIds)
+	if err != nil {
+		return money.Money{}, pricingAppError(err)
+	}
+
+	tax := money.Zero(lines[0].Total.Currency)
+	for _, line := range lines {
+		tax = tax.Add(line.Total.Percent(basisPoints[line.ProductID]))
+	}
+
+	return tax, nil
+}
+
+// pricingAppError maps the errors of the rate operations to responses.
+func pricingAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, helper.ErrShippingRateNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Shipping Rate Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrTaxRateNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Tax Rate Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrCategoryNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Category Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrShippingRateAlreadyExists) || errors.Is(err, helper.ErrTaxRateAlreadyExists) {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Rate Already Exists",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/imaging"
+	"mini-ecommerce/internal/storage"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// thumbnailSize is the longest side of a thumbnail in pixels.
+const thumbnailSize = 320
+
+type productImageServiceImpl struct {
+	tx                     *helper.Transaction
+	productRepository      product.Repository
+	productImageRepository product.ImageRepository
+	blob                   storage.Blob
+	maxBytes               int64
+}
+
+// NewProductImage stores uploads of at most maxBytes in blob.
+func NewProductImage(tx *helper.Transaction, productRepository product.Repository, productImageRepository product.ImageRepository, blob storage.Blob, maxBytes int64) product.ImageService {
+	return &productImageServiceImpl{
+		tx:                     tx,
+		productRepository:      productRepository,
+		productImageRepository: productImageRepository,
+		blob:                   blob,
+		maxBytes:               maxBytes,
+	}
+}
+
+func (p *productImageServiceImpl) Upload(ctx context.Context, productId string, data []byte) (product.Image, *helper.AppError) {
+	if int64(len(data)) > p.maxBytes {
+		return product.Image{}, imageAppError(helper.ErrImageTooLarge)
+	}
+
+	decoded, err := imaging.Decode(data)
+	if err != nil {
+		return product.Image{}, imageAppError(err)
+	}
+
+	// Fail before writing any file when the product does not exist.
+	if _, err := p.productRepository.Find(ctx, productId); err != nil {
+		return product.Image{}, imageAppError(err)
+	}
+
+	var thumbnail bytes.Buffer
+	if err := imaging.EncodeJPEG(&thumbnail, imaging.Thumbnail(decoded.Image, thumbnailSize)); err != nil {
+		return product.Image{}, imageAppError(err)
+	}
+
+	name := uuid.NewString()
+	image := product.Image{
+		ProductID:    productId,
+		Key:          fmt.Sprintf("products/%s/%s%s", productId, name, decoded.Extension),
+		ThumbnailKey: fmt.Sprintf("products/%s/%s_thumb.jpg", productId, name),
+		ContentType:  decoded.ContentType,
+		Width:        decoded.Width,
+		Height:       decoded.Height,
+	}
+
+	err = p.blob.Put(ctx, image.Key, bytes.NewReader(data), image.ContentType)
+	if err == nil {
+		err = p.blob.Put(ctx, image.ThumbnailKey, &thumbnail, "image/jpeg")
+	}
+
+	if err == nil {
+		err = p.tx.ExecTx(ctx, func(ctx context.Context) error {
+			return p.productImageRepository.Create(ctx, &image)
+		})
+	}
+
+	if err != nil {
+		deleteImageFiles(p.blob, image)
+		return product.Image{}, imageAppError(err)
+	}
+
+	resolveImageURLs(p.blob, &image)
+	return image, nil
+}
+
+func (p *productImageServiceImpl) SetPrimary(ctx context.Context, productId string, imageId string) *helper.AppError {
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		return p.productImageRepository.SetPrimary(ctx, productId, imageId)
+	})
+
+	return imageAppError(err)
+}
+
+func (p *productImageServiceImpl) Reorder(ctx context.Context, productId string, imageIds []string) ([]product.Image, *helper.AppError) {
+	var images []product.Image
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		current, err := p.productImageRepository.FindByProductId(ctx, productId)
+		if err != nil {
+			return err
+		}
+
+		if len(current) == 0 {
+			if _, err := p.productRepository.Find(ctx, productId); err != nil {
+				return err
+			}
+		}
+
+		if !isPermutation(current, imageIds) {
+			return helper.ErrImageOrderInvalid
+		}
+
+		if err := p.productImageRepository.Reorder(ctx, productId, imageIds); err != nil {
+			return err
+		}
+
+		images, err = p.productImageRepository.FindByProductId(ctx, productId)
+		return err
+	})
+
+	if appErr := imageAppError(err); appErr != nil {
+		return nil, appErr
+	}
+
+	for i := range images {
+		resolveImageURLs(p.blob, &images[i])
+	}
+
+	return images, nil
+}
+
+// isPermutation reports whether ids names every image exactly once.
+func isPermutation(images []product.Image, ids []string) bool {
+	if len(images) != len(ids) {
+		return false
+	}
+
+	remaining := make(map[string]bool, len(images))
+	for _, image := range images {
+		remaining[image.ID] = true
+	}
+
+	for _, id := range ids {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+
+	return true
+}
+
+// Delete removes the image and, when it was the primary one, promotes the
+// next image in display order.
+func (p *productImageServiceImpl) Delete(ctx context.Context, productId string, imageId string) *helper.AppError {
+	var image product.Image
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		var err error
+		image, err = p.productImageRepository.FindById(ctx, imageId)
+		if err != nil {
+			return err
+		}
+
+		if image.ProductID != productId {
+			return helper.ErrImageNotFound
+		}
+
+		if err := p.productImageRepository.Delete(ctx, imageId); err != nil {
+			return err
+		}
+
+		if !image.Primary {
+			return nil
+		}
+
+		remaining, err := p.productImageRepository.FindByProductId(ctx, productId)
+		if err != nil || len(remaining) == 0 {
+			return err
+		}
+
+		return p.productImageRepository.SetPrimary(ctx, productId, remaining[0].ID)
+	})
+
+	if appErr := imageAppError(err); appErr != nil {
+		return appErr
+	}
+
+	deleteImageFiles(p.blob, image)
+	return nil
+}
+
+// imageAppError maps the errors of the image operations to responses.
+func imageAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, helper.ErrProductNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Product Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrImageNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Image Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrImageTooLarge) {
+		return helper.NewAppError(
+			http.StatusRequestEntityTooLarge,
+			"Image Too Large",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrImageUnsupported) {
+		return helper.NewAppError(
+			http.StatusUnsupportedMediaType,
+			"Unsupported Image",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrImageDimensions) {
+		return helper.NewAppError(
+			http.StatusUnprocessableEntity,
+			"Invalid Image",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrImageOrderInvalid) {
+		return helper.NewAppError(
+			http.StatusUnprocessableEntity,
+			"Invalid Image Order",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
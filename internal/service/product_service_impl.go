@@ -3,21 +3,91 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"mini-ecommerce/internal/domain/category"
 	"mini-ecommerce/internal/domain/product"
 	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"mini-ecommerce/internal/storage"
 	"net/http"
 )
 
 type productServiceImpl struct {
-	productRepository product.Repository
+	tx                       *helper.Transaction
+	productRepository        product.Repository
+	productVariantRepository product.VariantRepository
+	productImageRepository   product.ImageRepository
+	categoryRepository       category.Repository
+	blob                     storage.Blob
+	currency                 string
 }
 
-func NewProduct(productRepository product.Repository) product.Service {
-	return &productServiceImpl{productRepository: productRepository}
+// NewProduct prices products without an explicit currency in currency, the
+// store currency. Image files are resolved to URLs and deleted through blob.
+func NewProduct(tx *helper.Transaction, productRepository product.Repository, productVariantRepository product.VariantRepository, productImageRepository product.ImageRepository, categoryRepository category.Repository, blob storage.Blob, currency string) product.Service {
+	return &productServiceImpl{
+		tx:                       tx,
+		productRepository:        productRepository,
+		productVariantRepository: productVariantRepository,
+		productImageRepository:   productImageRepository,
+		categoryRepository:       categoryRepository,
+		blob:                     blob,
+		currency:                 currency,
+	}
+}
+
+// normalizePrice fills in the store currency and rejects codes money does not
+// know how to format.
+func (p *productServiceImpl) normalizePrice(price *money.Money) *helper.AppError {
+	if price.Currency == "" {
+		price.Currency = p.currency
+	}
+	*price = money.New(price.Amount, price.Currency)
+
+	if !money.IsSupportedCurrency(price.Currency) {
+		return helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request",
+			helper.ErrCurrencyUnsupported,
+		)
+	}
+
+	return nil
 }
 
-func (p *productServiceImpl) Create(ctx context.Context, data *product.Data) *helper.AppError {
-	err := p.productRepository.Create(ctx, data)
+func (p *productServiceImpl) Create(ctx context.Context, detail *product.Detail) *helper.AppError {
+	if appErr := p.normalizePrice(&detail.Data.Price); appErr != nil {
+		return appErr
+	}
+
+	if len(detail.Variants) == 0 {
+		detail.Variants = []product.Variant{{Stock: detail.Data.Stock}}
+	}
+
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := p.productRepository.Create(ctx, &detail.Data); err != nil {
+			return err
+		}
+
+		detail.Data.Stock = 0
+		for i := range detail.Variants {
+			variant := &detail.Variants[i]
+			variant.ProductID = detail.Data.ID
+			if variant.SKU == "" {
+				variant.SKU = fmt.Sprintf("P%s-%d", detail.Data.ID, i+1)
+			}
+			p.applyProductPrice(variant, detail.Data.Price)
+
+			if err := p.productVariantRepository.Create(ctx, variant); err != nil {
+				return err
+			}
+			detail.Data.Stock += variant.Stock
+		}
+
+		return nil
+	})
+
 	if err != nil {
 		if errors.Is(err, helper.ErrProductAlreadyExists) {
 			return helper.NewAppError(
@@ -27,6 +97,14 @@ func (p *productServiceImpl) Create(ctx context.Context, data *product.Data) *he
 			)
 		}
 
+		if errors.Is(err, helper.ErrVariantAlreadyExists) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Variant Already Exists",
+				err,
+			)
+		}
+
 		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -37,42 +115,147 @@ func (p *productServiceImpl) Create(ctx context.Context, data *product.Data) *he
 	return nil
 }
 
-func (p *productServiceImpl) Get(ctx context.Context, id string) (product.Data, *helper.AppError) {
-	productData, err := p.productRepository.Find(ctx, id)
+func (p *productServiceImpl) Get(ctx context.Context, id string) (product.Detail, *helper.AppError) {
+	var detail product.Detail
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		var err error
+		detail.Data, err = p.productRepository.Find(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		detail.Variants, err = p.productVariantRepository.FindByProductId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		detail.Images, err = p.productImageRepository.FindByProductId(ctx, id)
+		return err
+	})
+
 	if err != nil {
 		if errors.Is(err, helper.ErrProductNotFound) {
-			return product.Data{}, helper.NewAppError(
+			return product.Detail{}, helper.NewAppError(
 				http.StatusNotFound,
 				"Product Not Found",
 				err,
 			)
 		}
 
-		return product.Data{}, helper.NewAppError(
+		return product.Detail{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	resolveImageURLs(p.blob, detail.Data.PrimaryImage)
+	for i := range detail.Images {
+		resolveImageURLs(p.blob, &detail.Images[i])
+	}
+
+	return detail, nil
+}
+
+func (p productServiceImpl) GetAll(ctx context.Context, filter product.Filter) ([]product.Data, helper.Pagination, *helper.AppError) {
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, helper.Pagination{}, helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request",
+			errors.New("Minimum price must not be greater than maximum price"),
+		)
+	}
+
+	var err error
+	if filter.IncludeDescendants && filter.CategoryID != "" {
+		filter.CategoryIDs, err = p.categoryRepository.FindDescendantIds(ctx, filter.CategoryID)
+		if err != nil {
+			return nil, helper.Pagination{}, helper.NewAppError(
+				http.StatusInternalServerError,
+				"Internal Server Error",
+				err,
+			)
+		}
+	}
+
+	products, total, err := p.productRepository.FindAll(ctx, filter)
+	if err != nil {
+		return nil, helper.Pagination{}, helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
 			err,
 		)
 	}
 
-	return productData, nil
+	for i := range products {
+		resolveImageURLs(p.blob, products[i].PrimaryImage)
+	}
+
+	return products, helper.NewPagination(filter.Page, total), nil
 }
 
-func (p productServiceImpl) GetAll(ctx context.Context) ([]product.Data, *helper.AppError) {
-	products, err := p.productRepository.FindAll(ctx)
+func (p *productServiceImpl) Search(ctx context.Context, query string, page helper.PageRequest) ([]product.SearchResult, helper.Pagination, *helper.AppError) {
+	if len(product.SearchTerms(query)) == 0 {
+		return nil, helper.Pagination{}, helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Search Query",
+			helper.ErrSearchQueryEmpty,
+		)
+	}
+
+	results, total, err := p.productRepository.Search(ctx, query, page)
 	if err != nil {
-		return nil, helper.NewAppError(
+		return nil, helper.Pagination{}, helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
 			err,
 		)
 	}
 
-	return products, nil
+	for i := range results {
+		resolveImageURLs(p.blob, results[i].Data.PrimaryImage)
+	}
+
+	return results, helper.NewPagination(page, total), nil
 }
 
 func (p *productServiceImpl) Update(ctx context.Context, update *product.Update) *helper.AppError {
-	err := p.productRepository.Update(ctx, update)
+	if update.Price != nil {
+		if appErr := p.normalizePrice(update.Price); appErr != nil {
+			return appErr
+		}
+	}
+
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if update.Price != nil {
+			if err := p.checkCurrencyChange(ctx, update.ID, update.Price.Currency); err != nil {
+				return err
+			}
+		}
+
+		if update.Stock != nil {
+			variants, err := p.productVariantRepository.FindByProductId(ctx, update.ID)
+			if err != nil {
+				return err
+			}
+
+			switch len(variants) {
+			case 0:
+				return helper.ErrProductNotFound
+			case 1:
+				if err := p.productVariantRepository.Update(ctx, &product.VariantUpdate{
+					ID:    variants[0].ID,
+					Stock: update.Stock,
+				}); err != nil {
+					return err
+				}
+			default:
+				return helper.ErrProductStockPerVariant
+			}
+		}
+
+		return p.productRepository.Update(ctx, update)
+	})
 
 	if err != nil {
 		if errors.Is(err, helper.ErrProductNotFound) {
@@ -83,6 +266,22 @@ func (p *productServiceImpl) Update(ctx context.Context, update *product.Update)
 			)
 		}
 
+		if errors.Is(err, helper.ErrProductStockPerVariant) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Stock Is Set Per Variant",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrProductCurrencyOverridden) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Product Currency Cannot Change",
+				err,
+			)
+		}
+
 		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -93,8 +292,50 @@ func (p *productServiceImpl) Update(ctx context.Context, update *product.Update)
 	return nil
 }
 
+// checkCurrencyChange refuses to move product id to currency while any of its
+// variants overrides the price, since the override amounts would be read in
+// the new currency. The product stays locked so no override is added
+// meanwhile.
+func (p *productServiceImpl) checkCurrencyChange(ctx context.Context, id string, currency string) error {
+	if err := p.productRepository.Lock(ctx, id); err != nil {
+		return err
+	}
+
+	productData, err := p.productRepository.Find(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if productData.Price.Currency == currency {
+		return nil
+	}
+
+	variants, err := p.productVariantRepository.FindByProductId(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range variants {
+		if variant.PriceOverride {
+			return helper.ErrProductCurrencyOverridden
+		}
+	}
+
+	return nil
+}
+
 func (p *productServiceImpl) Delete(ctx context.Context, id string) *helper.AppError {
-	err := p.productRepository.Delete(ctx, id)
+	var images []product.Image
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		var err error
+		images, err = p.productImageRepository.FindByProductId(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// The image rows go with the product; their files are removed below.
+		return p.productRepository.Delete(ctx, id)
+	})
 
 	if err != nil {
 		if errors.Is(err, helper.ErrProductNotFound) {
@@ -105,6 +346,14 @@ func (p *productServiceImpl) Delete(ctx context.Context, id string) *helper.AppE
 			)
 		}
 
+		if errors.Is(err, helper.ErrProductInUse) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Product Cannot Be Deleted",
+				err,
+			)
+		}
+
 		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
@@ -112,5 +361,214 @@ func (p *productServiceImpl) Delete(ctx context.Context, id string) *helper.AppE
 		)
 	}
 
+	for _, image := range images {
+		deleteImageFiles(p.blob, image)
+	}
+
 	return nil
 }
+
+// applyProductPrice resolves the effective price of variant, whose Price.Amount
+// is only meaningful as an override.
+func (p *productServiceImpl) applyProductPrice(variant *product.Variant, productPrice money.Money) {
+	if variant.Options == nil {
+		variant.Options = map[string]string{}
+	}
+
+	if variant.PriceOverride {
+		variant.Price = money.New(variant.Price.Amount, productPrice.Currency)
+		return
+	}
+	variant.Price = productPrice
+}
+
+func (p *productServiceImpl) AddVariant(ctx context.Context, variant *product.Variant) *helper.AppError {
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		// Locked so the product currency cannot change under a new price
+		// override.
+		if err := p.productRepository.Lock(ctx, variant.ProductID); err != nil {
+			return err
+		}
+
+		productData, err := p.productRepository.Find(ctx, variant.ProductID)
+		if err != nil {
+			return err
+		}
+
+		p.applyProductPrice(variant, productData.Price)
+
+		return p.productVariantRepository.Create(ctx, variant)
+	})
+
+	return variantAppError(err)
+}
+
+func (p *productServiceImpl) UpdateVariant(ctx context.Context, update product.VariantUpdate) (product.Variant, *helper.AppError) {
+	var variant product.Variant
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		current, err := p.productVariantRepository.FindById(ctx, update.ID)
+		if err != nil {
+			return err
+		}
+
+		if current.ProductID != update.ProductID {
+			return helper.ErrVariantNotFound
+		}
+
+		// Locked so the product currency cannot change under a new price
+		// override.
+		if update.Price != nil {
+			if err := p.productRepository.Lock(ctx, update.ProductID); err != nil {
+				return err
+			}
+		}
+
+		if err := p.productVariantRepository.Update(ctx, &update); err != nil {
+			return err
+		}
+
+		variant, err = p.productVariantRepository.FindById(ctx, update.ID)
+		return err
+	})
+
+	if appErr := variantAppError(err); appErr != nil {
+		return product.Variant{}, appErr
+	}
+
+	return variant, nil
+}
+
+func (p *productServiceImpl) DeleteVariant(ctx context.Context, productId string, variantId string) *helper.AppError {
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		// Without the lock, concurrent deletes could each see another variant
+		// left and remove the last two together.
+		if err := p.productRepository.Lock(ctx, productId); err != nil {
+			return err
+		}
+
+		variants, err := p.productVariantRepository.FindByProductId(ctx, productId)
+		if err != nil {
+			return err
+		}
+
+		found := false
+		for _, variant := range variants {
+			if variant.ID == variantId {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return helper.ErrVariantNotFound
+		}
+
+		if len(variants) == 1 {
+			return helper.ErrProductLastVariant
+		}
+
+		return p.productVariantRepository.Delete(ctx, variantId)
+	})
+
+	return variantAppError(err)
+}
+
+// variantAppError maps the errors of the variant operations to responses.
+func variantAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, helper.ErrProductNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Product Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrVariantNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Variant Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrVariantAlreadyExists) {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Variant Already Exists",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrProductLastVariant) || errors.Is(err, helper.ErrVariantInUse) {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Variant Cannot Be Deleted",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
+
+// resolveVariant finds the variant an order or cart line refers to. A line may
+// name only the product when it has a single variant; a variantId that does
+// not belong to productId is reported as not found.
+func resolveVariant(ctx context.Context, productVariantRepository product.VariantRepository, productId string, variantId string) (product.Variant, error) {
+	if variantId != "" {
+		variant, err := productVariantRepository.FindById(ctx, variantId)
+		if err != nil {
+			return product.Variant{}, err
+		}
+
+		if productId != "" && variant.ProductID != productId {
+			return product.Variant{}, helper.ErrVariantNotFound
+		}
+
+		return variant, nil
+	}
+
+	variants, err := productVariantRepository.FindByProductId(ctx, productId)
+	if err != nil {
+		return product.Variant{}, err
+	}
+
+	switch len(variants) {
+	case 0:
+		return product.Variant{}, helper.ErrProductNotFound
+	case 1:
+		return variants[0], nil
+	default:
+		return product.Variant{}, helper.ErrVariantRequired
+	}
+}
+
+// resolveImageURLs fills in where clients fetch image and its thumbnail. A nil
+// image is left alone.
+func resolveImageURLs(blob storage.Blob, image *product.Image) {
+	if image == nil {
+		return
+	}
+
+	image.URL = blob.URL(image.Key)
+	image.ThumbnailURL = blob.URL(image.ThumbnailKey)
+}
+
+// deleteImageFiles removes the files of an image whose row is already gone. A
+// failure only leaves an orphaned file behind, so it is logged rather than
+// reported to the client.
+func deleteImageFiles(blob storage.Blob, image product.Image) {
+	// The request may already be cancelled by now.
+	for _, key := range []string{image.Key, image.ThumbnailKey} {
+		if err := blob.Delete(context.Background(), key); err != nil {
+			log.Printf("[STORAGE] delete %q : %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/promotion"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type promotionServiceImpl struct {
+	tx                   *helper.Transaction
+	couponRepository     promotion.Repository
+	redemptionRepository promotion.RedemptionRepository
+	currency             string
+}
+
+// NewPromotion prices coupon amounts without an explicit currency in
+// currency, the store currency.
+func NewPromotion(tx *helper.Transaction, couponRepository promotion.Repository, redemptionRepository promotion.RedemptionRepository, currency string) promotion.Service {
+	return &promotionServiceImpl{
+		tx:                   tx,
+		couponRepository:     couponRepository,
+		redemptionRepository: redemptionRepository,
+		currency:             currency,
+	}
+}
+
+// normalizeCouponCode makes codes case-insensitive.
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (p *promotionServiceImpl) Create(ctx context.Context, coupon *promotion.Coupon) *helper.AppError {
+	coupon.Code = normalizeCouponCode(coupon.Code)
+
+	currency := coupon.AmountOff.Currency
+	if currency == "" {
+		currency = p.currency
+	}
+	coupon.AmountOff = money.New(coupon.AmountOff.Amount, currency)
+	coupon.MinOrder = money.New(coupon.MinOrder.Amount, currency)
+
+	if !money.IsSupportedCurrency(coupon.AmountOff.Currency) {
+		return helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Request",
+			helper.ErrCurrencyUnsupported,
+		)
+	}
+
+	valid := coupon.Code != ""
+	switch coupon.Kind {
+	case promotion.KindPercentage:
+		valid = valid && coupon.PercentOff > 0 && coupon.PercentOff <= 10000 && coupon.AmountOff.IsZero()
+	case promotion.KindFixedAmount:
+		valid = valid && coupon.PercentOff == 0 && coupon.AmountOff.Amount > 0
+	case promotion.KindFreeShipping:
+		valid = valid && coupon.PercentOff == 0 && coupon.AmountOff.IsZero()
+	default:
+		valid = false
+	}
+
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.StartsAt.Before(*coupon.EndsAt) {
+		valid = false
+	}
+
+	if !valid {
+		return helper.NewAppError(
+			http.StatusBadRequest,
+			"Invalid Coupon",
+			helper.ErrCouponInvalid,
+		)
+	}
+
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		return p.couponRepository.Create(ctx, coupon)
+	})
+
+	if err != nil {
+		if errors.Is(err, helper.ErrCouponAlreadyExists) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"Coupon Already Exists",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrProductNotFound) {
+			return helper.NewAppError(
+				http.StatusNotFound,
+				"Product Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrCategoryNotFound) {
+			return helper.NewAppError(
+				http.StatusNotFound,
+				"Category Not Found",
+				err,
+			)
+		}
+
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return nil
+}
+
+func (p *promotionServiceImpl) Get(ctx context.Context, id string) (promotion.Coupon, *helper.AppError) {
+	coupon, err := p.couponRepository.FindById(ctx, id)
+	if err != nil {
+		return promotion.Coupon{}, couponAppError(err)
+	}
+
+	return coupon, nil
+}
+
+func (p *promotionServiceImpl) GetAll(ctx context.Context, page helper.PageRequest) ([]promotion.Coupon, helper.Pagination, *helper.AppError) {
+	coupons, total, err := p.couponRepository.FindAll(ctx, page)
+	if err != nil {
+		return nil, helper.Pagination{}, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return coupons, helper.NewPagination(page, total), nil
+}
+
+func (p *promotionServiceImpl) Deactivate(ctx context.Context, id string) *helper.AppError {
+	return couponAppError(p.couponRepository.Deactivate(ctx, id))
+}
+
+// Apply compares the minimum order with the subtotal of every line, while the
+// discount is only taken off the lines in the coupon's scope. A fixed amount
+// never exceeds those lines.
+func (p *promotionServiceImpl) Apply(ctx context.Context, userId int, code string, lines []promotion.Line) (promotion.Discount, *helper.AppError) {
+	var discount promotion.Discount
+	err := p.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if len(lines) == 0 {
+			return helper.ErrCouponNotApplicable
+		}
+
+		coupon, err := p.couponRepository.FindByCodeForUpdate(ctx, normalizeCouponCode(code))
+		if err != nil {
+			return err
+		}
+
+		if !coupon.Active {
+			return helper.ErrCouponNotFound
+		}
+
+		now := time.Now()
+		if (coupon.StartsAt != nil && now.Before(*coupon.StartsAt)) || (coupon.EndsAt != nil && !now.Before(*coupon.EndsAt)) {
+			return helper.ErrCouponNotActive
+		}
+
+		subtotal := money.Zero(lines[0].Total.Currency)
+		for _, line := range lines {
+			subtotal = subtotal.Add(line.Total)
+		}
+
+		// Amounts only bind orders in the coupon currency; a percentage
+		// coupon without a minimum works in any currency.
+		if (!coupon.AmountOff.IsZero() || !coupon.MinOrder.IsZero()) && !subtotal.SameCurrency(coupon.AmountOff) {
+			return helper.ErrCouponNotApplicable
+		}
+
+		if !coupon.MinOrder.IsZero() && subtotal.Amount < coupon.MinOrder.Amount {
+			return helper.ErrCouponMinimumNotMet
+		}
+
+		used, usedByUser, err := p.redemptionRepository.Count(ctx, coupon.ID, userId)
+		if err != nil {
+			return err
+		}
+
+		if coupon.UsageLimit > 0 && used >= coupon.UsageLimit {
+			return helper.ErrCouponUsageExceeded
+		}
+
+		if coupon.PerUserLimit > 0 && usedByUser >= coupon.PerUserLimit {
+			return helper.ErrCouponUserLimitExceeded
+		}
+
+		// eligibleTotals keeps the lines outside the coupon scope at zero, so
+		// they get no share of the discount.
+		eligibleTotals := make([]money.Money, 0, len(lines))
+		for _, line := range lines {
+			eligibleTotals = append(eligibleTotals, line.Total)
+		}
+
+		eligible := subtotal
+		if len(coupon.ProductIDs) > 0 || len(coupon.CategoryIDs) > 0 {
+			var productIds []string
+			for _, line := range lines {
+				productIds = append(productIds, line.ProductID)
+			}
+
+			eligibleIds, err := p.couponRepository.FindEligibleProductIds(ctx, coupon.ID, productIds)
+			if err != nil {
+				return err
+			}
+
+			inScope := map[string]bool{}
+			for _, productId := range eligibleIds {
+				inScope[productId] = true
+			}
+
+			eligible = money.Zero(subtotal.Currency)
+			for i, line := range lines {
+				if inScope[line.ProductID] {
+					eligible = eligible.Add(line.Total)
+				} else {
+					eligibleTotals[i] = money.Zero(subtotal.Currency)
+				}
+			}
+		}
+
+		if eligible.IsZero() {
+			return helper.ErrCouponNotApplicable
+		}
+
+		discount = promotion.Discount{
+			CouponID: coupon.ID,
+			Code:     coupon.Code,
+			Amount:   money.Zero(subtotal.Currency),
+		}
+
+		switch coupon.Kind {
+		case promotion.KindPercentage:
+			discount.Amount = eligible.Percent(coupon.PercentOff)
+		case promotion.KindFixedAmount:
+			discount.Amount = coupon.AmountOff.Min(eligible)
+		case promotion.KindFreeShipping:
+			discount.FreeShipping = true
+		}
+		discount.Shares = discount.Amount.Allocate(eligibleTotals)
+
+		return nil
+	})
+
+	if appErr := couponAppError(err); appErr != nil {
+		return promotion.Discount{}, appErr
+	}
+
+	return discount, nil
+}
+
+func (p *promotionServiceImpl) Redeem(ctx context.Context, userId int, orderId int, discount promotion.Discount) *helper.AppError {
+	err := p.redemptionRepository.Create(ctx, &promotion.Redemption{
+		CouponID: discount.CouponID,
+		UserID:   userId,
+		OrderID:  orderId,
+		Discount: discount.Amount,
+	})
+
+	return couponAppError(err)
+}
+
+func (p *promotionServiceImpl) Release(ctx context.Context, orderId int) *helper.AppError {
+	return couponAppError(p.redemptionRepository.DeleteByOrderId(ctx, orderId))
+}
+
+// couponAppError maps the errors of the coupon operations to responses.
+func couponAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, helper.ErrCouponNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Coupon Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrCouponUsageExceeded) || errors.Is(err, helper.ErrCouponUserLimitExceeded) {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Coupon Used Up",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrCouponNotActive) || errors.Is(err, helper.ErrCouponMinimumNotMet) || errors.Is(err, helper.ErrCouponNotApplicable) {
+		return helper.NewAppError(
+			http.StatusUnprocessableEntity,
+			"Coupon Not Applicable",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
@@ -0,0 +1,688 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/domain/payment"
+	"mini-ecommerce/internal/domain/product"
+	"mini-ecommerce/internal/domain/rma"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"mini-ecommerce/internal/money"
+	"net/http"
+	"time"
+)
+
+type returnServiceImpl struct {
+	tx                       *helper.Transaction
+	returnRepository         rma.Repository
+	refundRepository         rma.RefundRepository
+	orderRepository          order.Repository
+	orderItemRepository      order.ItemRepository
+	paymentRepository        payment.Repository
+	productVariantRepository product.VariantRepository
+	gateway                  payment.Gateway
+	orderService             order.Service
+	gatewayTimeout           time.Duration
+	reconcileAfter           time.Duration
+}
+
+// NewReturn moves orders through orderService, so refunding follows the order
+// state machine. Each refund sent to gateway is bounded by gatewayTimeout, and
+// Reconcile retries refunds pending for longer than reconcileAfter.
+func NewReturn(tx *helper.Transaction, returnRepository rma.Repository, refundRepository rma.RefundRepository, orderRepository order.Repository, orderItemRepository order.ItemRepository, paymentRepository payment.Repository, productVariantRepository product.VariantRepository, gateway payment.Gateway, orderService order.Service, gatewayTimeout time.Duration, reconcileAfter time.Duration) rma.Service {
+	return &returnServiceImpl{
+		tx:                       tx,
+		returnRepository:         returnRepository,
+		refundRepository:         refundRepository,
+		orderRepository:          orderRepository,
+		orderItemRepository:      orderItemRepository,
+		paymentRepository:        paymentRepository,
+		productVariantRepository: productVariantRepository,
+		gateway:                  gateway,
+		orderService:             orderService,
+		gatewayTimeout:           gatewayTimeout,
+		reconcileAfter:           reconcileAfter,
+	}
+}
+
+func (r *returnServiceImpl) Request(ctx context.Context, caller user.Caller, data *rma.Return) *helper.AppError {
+	err := r.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := r.returnRepository.LockOrder(ctx, data.OrderID); err != nil {
+			return err
+		}
+
+		orderData, err := r.orderRepository.FindById(ctx, data.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if !caller.Owns(orderData.UserID) {
+			return helper.ErrOrderNotFound
+		}
+
+		switch orderData.Status {
+		case order.StatusPaid, order.StatusShipped, order.StatusDelivered:
+		default:
+			return helper.ErrReturnNotAllowed
+		}
+
+		items, err := r.returnableItems(ctx, data.OrderID, data.Items)
+		if err != nil {
+			return err
+		}
+
+		data.UserID = orderData.UserID
+		data.Status = rma.StatusRequested
+		data.Refunded = money.Zero(orderData.TotalPrice.Currency)
+		data.Items = items
+		return r.returnRepository.Create(ctx, data)
+	})
+
+	return returnAppError(err)
+}
+
+// returnableItems checks requested against what is left to return of the
+// order and merges repeated order items.
+func (r *returnServiceImpl) returnableItems(ctx context.Context, orderId int, requested []rma.Item) ([]rma.Item, error) {
+	if len(requested) == 0 {
+		return nil, helper.ErrReturnItemsInvalid
+	}
+
+	orderItems, err := r.orderItemRepository.FindItems(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	returned, err := r.returnRepository.ReturnedQuantities(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := map[int]int{}
+	for _, item := range requested {
+		if item.Quantity <= 0 {
+			return nil, helper.ErrReturnItemsInvalid
+		}
+		quantities[item.OrderItemID] += item.Quantity
+	}
+
+	var items []rma.Item
+	for _, orderItem := range orderItems {
+		quantity, ok := quantities[orderItem.ID]
+		if !ok {
+			continue
+		}
+
+		if quantity > orderItem.Quantity-returned[orderItem.ID] {
+			return nil, helper.ErrReturnItemsInvalid
+		}
+
+		items = append(items, rma.Item{OrderItemID: orderItem.ID, Quantity: quantity})
+		delete(quantities, orderItem.ID)
+	}
+
+	// Whatever is left does not belong to the order.
+	if len(quantities) > 0 {
+		return nil, helper.ErrReturnItemsInvalid
+	}
+
+	return items, nil
+}
+
+func (r *returnServiceImpl) Get(ctx context.Context, caller user.Caller, id string) (rma.Return, *helper.AppError) {
+	data, err := r.find(ctx, caller, id)
+	if err != nil {
+		return rma.Return{}, returnAppError(err)
+	}
+
+	return data, nil
+}
+
+func (r *returnServiceImpl) GetByOrderId(ctx context.Context, caller user.Caller, orderId int) ([]rma.Return, *helper.AppError) {
+	orderData, err := r.orderRepository.FindById(ctx, orderId)
+	if err == nil && !caller.Owns(orderData.UserID) {
+		err = helper.ErrOrderNotFound
+	}
+
+	if err != nil {
+		return nil, returnAppError(err)
+	}
+
+	returns, err := r.returnRepository.FindByOrderId(ctx, orderId)
+	if err != nil {
+		return nil, returnAppError(err)
+	}
+
+	return returns, nil
+}
+
+func (r *returnServiceImpl) GetAll(ctx context.Context, page helper.PageRequest) ([]rma.Return, helper.Pagination, *helper.AppError) {
+	returns, total, err := r.returnRepository.FindAll(ctx, page)
+	if err != nil {
+		return nil, helper.Pagination{}, returnAppError(err)
+	}
+
+	return returns, helper.NewPagination(page, total), nil
+}
+
+func (r *returnServiceImpl) Approve(ctx context.Context, caller user.Caller, id string, note string) (rma.Return, *helper.AppError) {
+	return r.transition(ctx, caller, id, rma.StatusApproved, func(ctx context.Context, data rma.Return) error {
+		return r.returnRepository.UpdateStatus(ctx, data.ID, rma.StatusApproved, note)
+	})
+}
+
+func (r *returnServiceImpl) Reject(ctx context.Context, caller user.Caller, id string, note string) (rma.Return, *helper.AppError) {
+	return r.transition(ctx, caller, id, rma.StatusRejected, func(ctx context.Context, data rma.Return) error {
+		return r.returnRepository.UpdateStatus(ctx, data.ID, rma.StatusRejected, note)
+	})
+}
+
+func (r *returnServiceImpl) Receive(ctx context.Context, caller user.Caller, id string, restock bool) (rma.Return, *helper.AppError) {
+	return r.transition(ctx, caller, id, rma.StatusReceived, func(ctx context.Context, data rma.Return) error {
+		if err := r.returnRepository.UpdateStatus(ctx, data.ID, rma.StatusReceived, ""); err != nil {
+			return err
+		}
+
+		if !restock {
+			return nil
+		}
+
+		if err := r.restock(ctx, data); err != nil {
+			return err
+		}
+
+		return r.returnRepository.SetRestocked(ctx, data.ID)
+	})
+}
+
+// refundCaller moves orders to refunded once their refunds settle. Refunds
+// are started by admins and may be finished by Reconcile, which acts for no
+// one in particular.
+var refundCaller = user.Caller{Role: user.RoleAdmin}
+
+// Refund pays the return back in three steps so money never leaves without a
+// record of it: the pending refund is committed with the order locked, the
+// gateway is called outside any transaction, and the result is then applied
+// in a second transaction. A pending refund blocks further attempts on the
+// return until its result is in. When the gateway cannot tell how the refund
+// ended, it is returned still pending and Reconcile settles it later.
+func (r *returnServiceImpl) Refund(ctx context.Context, caller user.Caller, id string, amount *int64) (rma.Return, rma.Refund, *helper.AppError) {
+	var refund rma.Refund
+	data, appErr := r.transition(ctx, caller, id, rma.StatusRefunded, func(ctx context.Context, data rma.Return) error {
+		pending, err := r.refundRepository.HasPending(ctx, data.ID)
+		if err != nil {
+			return err
+		}
+
+		if pending {
+			return helper.ErrRefundInProgress
+		}
+
+		orderData, err := r.orderRepository.FindById(ctx, data.OrderID)
+		if err != nil {
+			return err
+		}
+
+		remaining, err := r.refundable(ctx, orderData, data)
+		if err != nil {
+			return err
+		}
+
+		refundAmount := remaining
+		if amount != nil {
+			refundAmount = money.New(*amount, remaining.Currency)
+		}
+
+		if refundAmount.Amount <= 0 || refundAmount.Amount > remaining.Amount {
+			return helper.ErrRefundAmountInvalid
+		}
+
+		paymentData, err := r.successfulPayment(ctx, data.OrderID)
+		if err != nil {
+			return err
+		}
+
+		refund = rma.Refund{
+			ReturnID:  data.ID,
+			PaymentID: paymentData.ID,
+			Amount:    refundAmount,
+			Status:    payment.StatusPending,
+		}
+		return r.refundRepository.Create(ctx, &refund)
+	})
+	if appErr != nil {
+		return rma.Return{}, rma.Refund{}, appErr
+	}
+
+	if appErr := returnAppError(r.settleRefund(ctx, &refund)); appErr != nil {
+		return rma.Return{}, rma.Refund{}, appErr
+	}
+
+	if refund.Status == payment.StatusFailed {
+		return data, refund, helper.NewAppError(
+			http.StatusBadGateway,
+			"Refund Failed",
+			helper.ErrRefundFailed,
+		)
+	}
+
+	data, err := r.returnRepository.FindById(ctx, data.ID)
+	if err != nil {
+		return rma.Return{}, rma.Refund{}, returnAppError(err)
+	}
+
+	return data, refund, nil
+}
+
+// settleRefund sends refund to the gateway and applies the result: on success
+// the amount is added to the return and the order. The return moves to
+// refunded once its whole value is paid back, and the order once all of it
+// came back. A gateway error leaves the refund
+// pending, since the money may still have gone out; repeating the refund
+// later returns its real outcome.
+func (r *returnServiceImpl) settleRefund(ctx context.Context, refund *rma.Refund) error {
+	// The client may go away mid-refund; the refund and its record must
+	// still be seen through.
+	ctx = context.WithoutCancel(ctx)
+
+	data, err := r.returnRepository.FindById(ctx, refund.ReturnID)
+	if err != nil {
+		return err
+	}
+
+	paymentData, err := r.paymentRepository.FindById(ctx, refund.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	refundCtx, cancel := context.WithTimeout(ctx, r.gatewayTimeout)
+	result, err := r.gateway.Refund(refundCtx, payment.Refund{
+		RefundID:        refund.ID,
+		PaymentID:       paymentData.ID,
+		OrderID:         data.OrderID,
+		ChargeReference: paymentData.Reference,
+		Amount:          refund.Amount,
+	})
+	cancel()
+	if err != nil {
+		log.Printf("[PAYMENT] refund %s left pending : %v", refund.ID, err)
+		return nil
+	}
+
+	return r.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := r.returnRepository.LockOrder(ctx, data.OrderID); err != nil {
+			return err
+		}
+
+		// Reconcile may have settled the refund while the gateway was being
+		// called.
+		current, err := r.refundRepository.FindById(ctx, refund.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != payment.StatusPending {
+			*refund = current
+			return nil
+		}
+
+		refund.Status = result.Status
+		refund.Reference = result.Reference
+		if err := r.refundRepository.Update(ctx, refund); err != nil {
+			return err
+		}
+
+		// A declined refund is kept on record and the return stays received,
+		// so it can be tried again.
+		if refund.Status != payment.StatusSuccess {
+			return nil
+		}
+
+		// Read again under the lock; other refunds of the order may have
+		// settled since.
+		data, err := r.returnRepository.FindById(ctx, data.ID)
+		if err != nil {
+			return err
+		}
+
+		orderData, err := r.orderRepository.FindById(ctx, data.OrderID)
+		if err != nil {
+			return err
+		}
+
+		remaining, err := r.refundable(ctx, orderData, data)
+		if err != nil {
+			return err
+		}
+
+		if err := r.returnRepository.AddRefunded(ctx, data.ID, refund.Amount); err != nil {
+			return err
+		}
+
+		if err := r.orderRepository.AddRefunded(ctx, data.OrderID, refund.Amount); err != nil {
+			return err
+		}
+
+		// A partial refund leaves the return received, so the rest of its
+		// value can be refunded later.
+		if refund.Amount.Amount < remaining.Amount {
+			return nil
+		}
+
+		if err := r.returnRepository.UpdateStatus(ctx, data.ID, rma.StatusRefunded, ""); err != nil {
+			return err
+		}
+
+		allRefunded, err := r.allItemsRefunded(ctx, data.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if !allRefunded {
+			return nil
+		}
+
+		if appErr := r.orderService.UpdateStatus(ctx, refundCaller, data.OrderID, order.StatusRefunded); appErr != nil {
+			return appErr
+		}
+
+		return nil
+	})
+}
+
+// Reconcile repeats every refund pending for longer than reconcileAfter and
+// applies how it ended. It returns how many refunds were settled; those the
+// gateway still cannot answer for stay pending.
+func (r *returnServiceImpl) Reconcile(ctx context.Context) (int, *helper.AppError) {
+	refunds, err := r.refundRepository.FindPendingBefore(ctx, time.Now().Add(-r.reconcileAfter))
+	if err != nil {
+		return 0, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	settled := 0
+	var errs []error
+	for _, refund := range refunds {
+		if err := r.settleRefund(ctx, &refund); err != nil {
+			errs = append(errs, fmt.Errorf("refund %s : %w", refund.ID, err))
+			continue
+		}
+		if refund.Status != payment.StatusPending {
+			settled++
+		}
+	}
+
+	if len(errs) > 0 {
+		return settled, helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			errors.Join(errs...),
+		)
+	}
+
+	return settled, nil
+}
+
+// transition checks that the return may move to next against the return
+// state machine and runs apply, which makes the move, in the same transaction
+// with the order locked. Returns the caller does not own are reported as not
+// found.
+func (r *returnServiceImpl) transition(ctx context.Context, caller user.Caller, id string, next rma.Status, apply func(ctx context.Context, data rma.Return) error) (rma.Return, *helper.AppError) {
+	var data rma.Return
+	err := r.tx.ExecTx(ctx, func(ctx context.Context) error {
+		current, err := r.find(ctx, caller, id)
+		if err != nil {
+			return err
+		}
+
+		if err := r.returnRepository.LockOrder(ctx, current.OrderID); err != nil {
+			return err
+		}
+
+		// Read again under the lock; the return may have just moved on.
+		current, err = r.returnRepository.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := rma.ValidateTransition(current.Status, next); err != nil {
+			return err
+		}
+
+		if err := apply(ctx, current); err != nil {
+			return err
+		}
+
+		data, err = r.returnRepository.FindById(ctx, id)
+		return err
+	})
+
+	if appErr := returnAppError(err); appErr != nil {
+		return rma.Return{}, appErr
+	}
+
+	return data, nil
+}
+
+// restock puts the returned items back into stock, locking their variants in
+// id order like every other stock change.
+func (r *returnServiceImpl) restock(ctx context.Context, data rma.Return) error {
+	orderItems, err := r.orderItemRepository.FindItems(ctx, data.OrderID)
+	if err != nil {
+		return err
+	}
+
+	variantIds := make(map[int]string, len(orderItems))
+	for _, orderItem := range orderItems {
+		variantIds[orderItem.ID] = orderItem.VariantID
+	}
+
+	var ids []string
+	for _, item := range data.Items {
+		ids = append(ids, variantIds[item.OrderItemID])
+	}
+
+	if _, err := r.productVariantRepository.FindForUpdate(ctx, ids); err != nil {
+		return err
+	}
+
+	for _, item := range data.Items {
+		if err := r.productVariantRepository.IncreaseStock(ctx, variantIds[item.OrderItemID], item.Quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refundable is what is left to refund on data: its value less what was
+// already refunded on it, never more than is left to refund on the order.
+func (r *returnServiceImpl) refundable(ctx context.Context, orderData order.Data, data rma.Return) (money.Money, error) {
+	value, err := r.returnValue(ctx, orderData, data)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return value.Sub(data.Refunded).Min(orderData.TotalPrice.Sub(orderData.Refunded)), nil
+}
+
+// returnValue is what the customer paid for the items of data: each order
+// item's share of the total without shipping, so including its discount and
+// tax, pro-rated by the quantity returned. The share of earlier refunded
+// returns is taken off cumulatively, so the last return of an order item gets
+// the rounding remainder and all its returns add up to its share.
+func (r *returnServiceImpl) returnValue(ctx context.Context, orderData order.Data, data rma.Return) (money.Money, error) {
+	orderItems, err := r.orderItemRepository.FindItems(ctx, orderData.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	refunded, err := r.refundedQuantities(ctx, orderData.ID)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	lineTotals := make([]money.Money, 0, len(orderItems))
+	for _, orderItem := range orderItems {
+		lineTotals = append(lineTotals, orderItem.LineTotal())
+	}
+	paid := orderData.TotalPrice.Sub(orderData.Shipping).Allocate(lineTotals)
+
+	quantities := make(map[int]int, len(data.Items))
+	for _, item := range data.Items {
+		quantities[item.OrderItemID] = item.Quantity
+	}
+
+	value := money.Zero(orderData.TotalPrice.Currency)
+	for i, orderItem := range orderItems {
+		quantity := quantities[orderItem.ID]
+		if quantity == 0 {
+			continue
+		}
+
+		before := refunded[orderItem.ID]
+		share := paid[i].Fraction(before+quantity, orderItem.Quantity).Sub(paid[i].Fraction(before, orderItem.Quantity))
+		value = value.Add(share)
+	}
+
+	return value, nil
+}
+
+// refundedQuantities returns how much of each order item was in a refunded
+// return, keyed by order item id.
+func (r *returnServiceImpl) refundedQuantities(ctx context.Context, orderId int) (map[int]int, error) {
+	returns, err := r.returnRepository.FindByOrderId(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := map[int]int{}
+	for _, data := range returns {
+		if data.Status != rma.StatusRefunded {
+			continue
+		}
+
+		for _, item := range data.Items {
+			quantities[item.OrderItemID] += item.Quantity
+		}
+	}
+
+	return quantities, nil
+}
+
+// allItemsRefunded reports whether every item of the order came back in a
+// refunded return. Shipping is never refunded, so the refunded amount alone
+// does not tell.
+func (r *returnServiceImpl) allItemsRefunded(ctx context.Context, orderId int) (bool, error) {
+	orderItems, err := r.orderItemRepository.FindItems(ctx, orderId)
+	if err != nil {
+		return false, err
+	}
+
+	refunded, err := r.refundedQuantities(ctx, orderId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, orderItem := range orderItems {
+		if refunded[orderItem.ID] < orderItem.Quantity {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (r *returnServiceImpl) successfulPayment(ctx context.Context, orderId int) (payment.Data, error) {
+	payments, err := r.paymentRepository.FindByOrderId(ctx, orderId)
+	if err != nil {
+		return payment.Data{}, err
+	}
+
+	for _, paymentData := range payments {
+		if paymentData.Status == payment.StatusSuccess {
+			return paymentData, nil
+		}
+	}
+
+	return payment.Data{}, helper.ErrRefundNoPayment
+}
+
+// find returns the return if caller owns it. Others get
+// helper.ErrReturnNotFound.
+func (r *returnServiceImpl) find(ctx context.Context, caller user.Caller, id string) (rma.Return, error) {
+	data, err := r.returnRepository.FindById(ctx, id)
+	if err != nil {
+		return rma.Return{}, err
+	}
+
+	if !caller.Owns(data.UserID) {
+		return rma.Return{}, helper.ErrReturnNotFound
+	}
+
+	return data, nil
+}
+
+// returnAppError maps the errors of the return operations to responses.
+func returnAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *helper.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	if errors.Is(err, helper.ErrReturnNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Return Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrOrderNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Order Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrReturnNotAllowed) || errors.Is(err, helper.ErrReturnInvalidTransition) || errors.Is(err, helper.ErrRefundNoPayment) || errors.Is(err, helper.ErrRefundInProgress) {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Invalid Return",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrReturnItemsInvalid) {
+		return helper.NewAppError(
+			http.StatusUnprocessableEntity,
+			"Invalid Return Items",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrRefundAmountInvalid) {
+		return helper.NewAppError(
+			http.StatusUnprocessableEntity,
+			"Invalid Refund Amount",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
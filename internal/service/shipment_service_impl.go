@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"mini-ecommerce/internal/domain/order"
+	"mini-ecommerce/internal/domain/shipment"
+	"mini-ecommerce/internal/domain/user"
+	"mini-ecommerce/internal/helper"
+	"net/http"
+	"time"
+)
+
+type shipmentServiceImpl struct {
+	tx                  *helper.Transaction
+	shipmentRepository  shipment.Repository
+	orderRepository     order.Repository
+	orderItemRepository order.ItemRepository
+	orderService        order.Service
+}
+
+// NewShipment moves orders through orderService, so shipping follows the
+// order state machine.
+func NewShipment(tx *helper.Transaction, shipmentRepository shipment.Repository, orderRepository order.Repository, orderItemRepository order.ItemRepository, orderService order.Service) shipment.Service {
+	return &shipmentServiceImpl{
+		tx:                  tx,
+		shipmentRepository:  shipmentRepository,
+		orderRepository:     orderRepository,
+		orderItemRepository: orderItemRepository,
+		orderService:        orderService,
+	}
+}
+
+func (s *shipmentServiceImpl) Create(ctx context.Context, caller user.Caller, data *shipment.Shipment) *helper.AppError {
+	err := s.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if err := s.shipmentRepository.LockOrder(ctx, data.OrderID); err != nil {
+			return err
+		}
+
+		orderData, err := s.orderRepository.FindById(ctx, data.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if !caller.Owns(orderData.UserID) {
+			return helper.ErrOrderNotFound
+		}
+
+		if orderData.Status != order.StatusPaid && orderData.Status != order.StatusShipped {
+			return helper.ErrShipmentNotAllowed
+		}
+
+		items, err := s.unshippedItems(ctx, data.OrderID, data.Items)
+		if err != nil {
+			return err
+		}
+
+		data.Items = items
+		data.Status = shipment.StatusShipped
+		if data.ShippedAt.IsZero() {
+			data.ShippedAt = time.Now()
+		}
+
+		if err := s.shipmentRepository.Create(ctx, data); err != nil {
+			return err
+		}
+
+		event := shipment.Event{
+			ShipmentID: data.ID,
+			Status:     shipment.StatusShipped,
+			OccurredAt: data.ShippedAt,
+		}
+		if err := s.shipmentRepository.CreateEvent(ctx, &event); err != nil {
+			return err
+		}
+		data.Events = []shipment.Event{event}
+
+		if orderData.Status == order.StatusPaid {
+			if appErr := s.orderService.UpdateStatus(ctx, caller, data.OrderID, order.StatusShipped); appErr != nil {
+				return appErr
+			}
+		}
+
+		return nil
+	})
+
+	return shipmentAppError(err)
+}
+
+// unshippedItems checks requested against what is left to ship of the order
+// and merges repeated order items. Without requested items it returns
+// everything left to ship.
+func (s *shipmentServiceImpl) unshippedItems(ctx context.Context, orderId int, requested []shipment.Item) ([]shipment.Item, error) {
+	orderItems, err := s.orderItemRepository.FindItems(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	shipped, err := s.shipmentRepository.ShippedQuantities(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := make(map[int]int, len(orderItems))
+	for _, orderItem := range orderItems {
+		remaining[orderItem.ID] = orderItem.Quantity - shipped[orderItem.ID]
+	}
+
+	quantities := map[int]int{}
+	if len(requested) == 0 {
+		for orderItemId, quantity := range remaining {
+			if quantity > 0 {
+				quantities[orderItemId] = quantity
+			}
+		}
+
+		if len(quantities) == 0 {
+			return nil, helper.ErrShipmentNothingToShip
+		}
+	}
+
+	for _, item := range requested {
+		if item.Quantity <= 0 {
+			return nil, helper.ErrShipmentItemsInvalid
+		}
+		quantities[item.OrderItemID] += item.Quantity
+	}
+
+	var items []shipment.Item
+	for _, orderItem := range orderItems {
+		quantity, ok := quantities[orderItem.ID]
+		if !ok {
+			continue
+		}
+
+		if quantity > remaining[orderItem.ID] {
+			return nil, helper.ErrShipmentItemsInvalid
+		}
+
+		items = append(items, shipment.Item{OrderItemID: orderItem.ID, Quantity: quantity})
+		delete(quantities, orderItem.ID)
+	}
+
+	// Whatever is left does not belong to the order.
+	if len(quantities) > 0 {
+		return nil, helper.ErrShipmentItemsInvalid
+	}
+
+	return items, nil
+}
+
+func (s *shipmentServiceImpl) Get(ctx context.Context, caller user.Caller, id string) (shipment.Shipment, *helper.AppError) {
+	data, err := s.find(ctx, caller, id)
+	if err != nil {
+		return shipment.Shipment{}, shipmentAppError(err)
+	}
+
+	return data, nil
+}
+
+func (s *shipmentServiceImpl) GetByOrderId(ctx context.Context, caller user.Caller, orderId int) ([]shipment.Shipment, *helper.AppError) {
+	orderData, err := s.orderRepository.FindById(ctx, orderId)
+	if err == nil && !caller.Owns(orderData.UserID) {
+		err = helper.ErrOrderNotFound
+	}
+
+	if err != nil {
+		return nil, shipmentAppError(err)
+	}
+
+	shipments, err := s.shipmentRepository.FindByOrderId(ctx, orderId)
+	if err != nil {
+		return nil, shipmentAppError(err)
+	}
+
+	return shipments, nil
+}
+
+func (s *shipmentServiceImpl) Update(ctx context.Context, caller user.Caller, update *shipment.Update) (shipment.Shipment, *helper.AppError) {
+	var data shipment.Shipment
+	err := s.tx.ExecTx(ctx, func(ctx context.Context) error {
+		if _, err := s.find(ctx, caller, update.ID); err != nil {
+			return err
+		}
+
+		if err := s.shipmentRepository.Update(ctx, update); err != nil {
+			return err
+		}
+
+		var err error
+		data, err = s.shipmentRepository.FindById(ctx, update.ID)
+		return err
+	})
+
+	if appErr := shipmentAppError(err); appErr != nil {
+		return shipment.Shipment{}, appErr
+	}
+
+	return data, nil
+}
+
+func (s *shipmentServiceImpl) AddEvent(ctx context.Context, caller user.Caller, event *shipment.Event) (shipment.Shipment, *helper.AppError) {
+	var data shipment.Shipment
+	err := s.tx.ExecTx(ctx, func(ctx context.Context) error {
+		current, err := s.find(ctx, caller, event.ShipmentID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.shipmentRepository.LockOrder(ctx, current.OrderID); err != nil {
+			return err
+		}
+
+		// Read again under the lock; another event may have just landed.
+		current, err = s.shipmentRepository.FindById(ctx, event.ShipmentID)
+		if err != nil {
+			return err
+		}
+
+		if err := shipment.ValidateProgress(current.Status, event.Status); err != nil {
+			return err
+		}
+
+		if event.OccurredAt.IsZero() {
+			event.OccurredAt = time.Now()
+		}
+
+		if err := s.shipmentRepository.CreateEvent(ctx, event); err != nil {
+			return err
+		}
+
+		if event.Status == shipment.StatusDelivered {
+			if err := s.deliverOrder(ctx, caller, current.OrderID); err != nil {
+				return err
+			}
+		}
+
+		data, err = s.shipmentRepository.FindById(ctx, event.ShipmentID)
+		return err
+	})
+
+	if appErr := shipmentAppError(err); appErr != nil {
+		return shipment.Shipment{}, appErr
+	}
+
+	return data, nil
+}
+
+// deliverOrder moves a shipped order to delivered once every item is shipped
+// and every shipment delivered.
+func (s *shipmentServiceImpl) deliverOrder(ctx context.Context, caller user.Caller, orderId int) error {
+	orderData, err := s.orderRepository.FindById(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	if orderData.Status != order.StatusShipped {
+		return nil
+	}
+
+	orderItems, err := s.orderItemRepository.FindItems(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	shipped, err := s.shipmentRepository.ShippedQuantities(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	for _, orderItem := range orderItems {
+		if shipped[orderItem.ID] < orderItem.Quantity {
+			return nil
+		}
+	}
+
+	shipments, err := s.shipmentRepository.FindByOrderId(ctx, orderId)
+	if err != nil {
+		return err
+	}
+
+	for _, data := range shipments {
+		if data.Status != shipment.StatusDelivered {
+			return nil
+		}
+	}
+
+	if appErr := s.orderService.UpdateStatus(ctx, caller, orderId, order.StatusDelivered); appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// find returns the shipment if caller owns its order. Others get
+// helper.ErrShipmentNotFound.
+func (s *shipmentServiceImpl) find(ctx context.Context, caller user.Caller, id string) (shipment.Shipment, error) {
+	data, err := s.shipmentRepository.FindById(ctx, id)
+	if err != nil {
+		return shipment.Shipment{}, err
+	}
+
+	orderData, err := s.orderRepository.FindById(ctx, data.OrderID)
+	if err != nil {
+		return shipment.Shipment{}, err
+	}
+
+	if !caller.Owns(orderData.UserID) {
+		return shipment.Shipment{}, helper.ErrShipmentNotFound
+	}
+
+	return data, nil
+}
+
+// shipmentAppError maps the errors of the shipment operations to responses.
+func shipmentAppError(err error) *helper.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *helper.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	if errors.Is(err, helper.ErrShipmentNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Shipment Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrOrderNotFound) {
+		return helper.NewAppError(
+			http.StatusNotFound,
+			"Order Not Found",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrShipmentNotAllowed) || errors.Is(err, helper.ErrShipmentNothingToShip) || errors.Is(err, helper.ErrShipmentInvalidStatus) {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Invalid Shipment",
+			err,
+		)
+	}
+
+	if errors.Is(err, helper.ErrShipmentItemsInvalid) {
+		return helper.NewAppError(
+			http.StatusUnprocessableEntity,
+			"Invalid Shipment Items",
+			err,
+		)
+	}
+
+	return helper.NewAppError(
+		http.StatusInternalServerError,
+		"Internal Server Error",
+		err,
+	)
+}
@@ -19,8 +19,16 @@ func NewUser(userRepository user.Repository) user.Service {
 }
 
 func (u *userServiceImpl) Create(ctx context.Context, data *user.Data) *helper.AppError {
-	hash, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+	data.Role = user.RoleCustomer
+	return u.create(ctx, data)
+}
 
+// BootstrapAdmin grants the admin role to the account with data.Email,
+// creating the account first when a password is given. It refuses to run once
+// any admin exists; later admins are appointed by an existing admin through
+// UpdateRole.
+func (u *userServiceImpl) BootstrapAdmin(ctx context.Context, data *user.Data) *helper.AppError {
+	count, err := u.userRepository.CountByRole(ctx, user.RoleAdmin)
 	if err != nil {
 		return helper.NewAppError(
 			http.StatusInternalServerError,
@@ -29,15 +37,75 @@ func (u *userServiceImpl) Create(ctx context.Context, data *user.Data) *helper.A
 		)
 	}
 
-	data.Password = string(hash)
+	if count > 0 {
+		return helper.NewAppError(
+			http.StatusConflict,
+			"Admin Already Exists",
+			helper.ErrAdminAlreadyExists,
+		)
+	}
 
-	err = u.userRepository.Create(ctx, data)
+	data.Role = user.RoleAdmin
+
+	if data.Password != "" {
+		return u.create(ctx, data)
+	}
+
+	if err := u.userRepository.UpdateRoleByEmail(ctx, data.Email, user.RoleAdmin); err != nil {
+		if errors.Is(err, helper.ErrUserNotFound) {
+			return helper.NewAppError(
+				http.StatusNotFound,
+				"User Not Found",
+				err,
+			)
+		}
+
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// UpdateRole refuses to make the last admin a customer, which would leave no
+// one able to appoint another.
+func (u *userServiceImpl) UpdateRole(ctx context.Context, id int, role user.Role) *helper.AppError {
+	err := func() error {
+		userData, err := u.userRepository.FindById(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if userData.Role == user.RoleAdmin && role != user.RoleAdmin {
+			count, err := u.userRepository.CountByRole(ctx, user.RoleAdmin)
+			if err != nil {
+				return err
+			}
+
+			if count <= 1 {
+				return helper.ErrAdminLast
+			}
+		}
+
+		return u.userRepository.UpdateRole(ctx, id, role)
+	}()
 
 	if err != nil {
-		if errors.Is(err, helper.ErrUserAlreadyExists) {
+		if errors.Is(err, helper.ErrUserNotFound) {
+			return helper.NewAppError(
+				http.StatusNotFound,
+				"User Not Found",
+				err,
+			)
+		}
+
+		if errors.Is(err, helper.ErrAdminLast) {
 			return helper.NewAppError(
 				http.StatusConflict,
-				"User Already Exists",
+				"Role Cannot Be Changed",
 				err,
 			)
 		}
@@ -52,25 +120,38 @@ func (u *userServiceImpl) Create(ctx context.Context, data *user.Data) *helper.A
 	return nil
 }
 
-func (u *userServiceImpl) GetByEmail(ctx context.Context, login user.Login) (user.Data, string, *helper.AppError) {
-	userData, accessToken, err := u.userRepository.FindByEmail(ctx, login)
+func (u *userServiceImpl) create(ctx context.Context, data *user.Data) *helper.AppError {
+	hash, err := bcrypt.GenerateFromPassword([]byte(data.Password), bcrypt.DefaultCost)
+
 	if err != nil {
-		if errors.Is(err, helper.ErrUserInvalid) {
-			return user.Data{}, "", helper.NewAppError(
-				http.StatusBadRequest,
-				"Validation Failed",
+		return helper.NewAppError(
+			http.StatusInternalServerError,
+			"Internal Server Error",
+			err,
+		)
+	}
+
+	data.Password = string(hash)
+
+	err = u.userRepository.Create(ctx, data)
+
+	if err != nil {
+		if errors.Is(err, helper.ErrUserAlreadyExists) {
+			return helper.NewAppError(
+				http.StatusConflict,
+				"User Already Exists",
 				err,
 			)
 		}
 
-		return user.Data{}, "", helper.NewAppError(
+		return helper.NewAppError(
 			http.StatusInternalServerError,
 			"Internal Server Error",
 			err,
 		)
 	}
 
-	return userData, accessToken, nil
+	return nil
 }
 
 func (u *userServiceImpl) Update(ctx context.Context, update *user.Update) *helper.AppError {
@@ -0,0 +1,20 @@
+// Package storage keeps uploaded files outside the database.
+package storage
+
+import (
+	"context"
+	"io"
+)
+
+// Blob stores opaque objects under slash separated keys such as
+// "products/12/photo.jpg". Implementations must be safe for concurrent use;
+// the local filesystem is the only one today, an S3-compatible bucket can be
+// added behind the same interface.
+type Blob interface {
+	// Put stores the content of r under key, replacing any existing object.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is where clients can fetch key.
+	URL(key string) string
+}